@@ -0,0 +1,208 @@
+package satellite
+
+import (
+	"errors"
+	"time"
+)
+
+// Batch propagates many satellites to a common time. The SGP4 coefficients of near earth satellites are copied into
+// one column per coefficient, so the propagation loop walks memory in order and allocates nothing. Deep space
+// satellites, whose resonance terms don't lend themselves to columns, are propagated one at a time with their own
+// records, which only allocates for the error of one that fails.
+//
+// A Batch keeps the outcome of its last propagation for Err, so it must not be propagated from several goroutines at
+// once.
+type Batch struct {
+	sats []*Satellite
+
+	// index into the near earth columns for each satellite, deepSpace or skipped for the others
+	near []int
+
+	// epoch as a two-part Julian date, for every satellite
+	jdsatepoch, jdsatepochF []float64
+
+	// near earth columns
+	radiusearthkm, xke, j2               []float64
+	simple                               []bool
+	bstar, inclo, nodeo, ecco, argpo, mo []float64
+	no, mdot, argpdot, nodedot, nodecf   []float64
+	cc1, cc4, cc5, t2cof, t3cof, t4cof   []float64
+	t5cof, omgcof, eta, xmcof, delmo     []float64
+	d2, d3, d4, sinmao                   []float64
+	con41, x1mth2, x7thm1, aycof, xlcof  []float64
+
+	// outcome of the last propagation for every satellite
+	tsince   []float64
+	errCode  []int
+	errValue []float64
+}
+
+// Values of Batch.near for satellites without near earth columns
+const (
+	deepSpace = -1
+	skipped   = -2
+)
+
+// ErrBatchLength is returned when the slices given to Batch.PropagateAt are shorter than the batch
+var ErrBatchLength = errors.New("output slices are shorter than the batch")
+
+// NewBatch copies the coefficients of sats into a Batch. Results are reported in the order of sats. Nil satellites
+// are skipped: their vectors are left zero and they have no error.
+func NewBatch(sats []*Satellite) *Batch {
+	n := len(sats)
+	b := &Batch{
+		sats:        sats,
+		near:        make([]int, n),
+		jdsatepoch:  make([]float64, n),
+		jdsatepochF: make([]float64, n),
+		tsince:      make([]float64, n),
+		errCode:     make([]int, n),
+		errValue:    make([]float64, n),
+	}
+
+	for i, sat := range sats {
+		if sat == nil {
+			b.near[i] = skipped
+			continue
+		}
+		b.jdsatepoch[i], b.jdsatepochF[i] = sat.jdsatepoch, sat.jdsatepochF
+		rec := &sat.rec
+		if rec.method == "d" {
+			b.near[i] = deepSpace
+			continue
+		}
+		b.near[i] = len(b.mo)
+
+		b.radiusearthkm = append(b.radiusearthkm, rec.whichconst.radiusearthkm)
+		b.xke = append(b.xke, rec.whichconst.xke)
+		b.j2 = append(b.j2, rec.whichconst.j2)
+		b.simple = append(b.simple, rec.isimp == 1)
+		b.bstar = append(b.bstar, rec.bstar)
+		b.inclo = append(b.inclo, rec.inclo)
+		b.nodeo = append(b.nodeo, rec.nodeo)
+		b.ecco = append(b.ecco, rec.ecco)
+		b.argpo = append(b.argpo, rec.argpo)
+		b.mo = append(b.mo, rec.mo)
+		b.no = append(b.no, rec.no)
+		b.mdot = append(b.mdot, rec.mdot)
+		b.argpdot = append(b.argpdot, rec.argpdot)
+		b.nodedot = append(b.nodedot, rec.nodedot)
+		b.nodecf = append(b.nodecf, rec.nodecf)
+		b.cc1 = append(b.cc1, rec.cc1)
+		b.cc4 = append(b.cc4, rec.cc4)
+		b.cc5 = append(b.cc5, rec.cc5)
+		b.t2cof = append(b.t2cof, rec.t2cof)
+		b.t3cof = append(b.t3cof, rec.t3cof)
+		b.t4cof = append(b.t4cof, rec.t4cof)
+		b.t5cof = append(b.t5cof, rec.t5cof)
+		b.omgcof = append(b.omgcof, rec.omgcof)
+		b.eta = append(b.eta, rec.eta)
+		b.xmcof = append(b.xmcof, rec.xmcof)
+		b.delmo = append(b.delmo, rec.delmo)
+		b.d2 = append(b.d2, rec.d2)
+		b.d3 = append(b.d3, rec.d3)
+		b.d4 = append(b.d4, rec.d4)
+		b.sinmao = append(b.sinmao, rec.sinmao)
+		b.con41 = append(b.con41, rec.con41)
+		b.x1mth2 = append(b.x1mth2, rec.x1mth2)
+		b.x7thm1 = append(b.x7thm1, rec.x7thm1)
+		b.aycof = append(b.aycof, rec.aycof)
+		b.xlcof = append(b.xlcof, rec.xlcof)
+	}
+	return b
+}
+
+// Len returns the number of satellites in the batch
+func (b *Batch) Len() int {
+	return len(b.sats)
+}
+
+// PropagateAt propagates every satellite in the batch to t, writing the position and velocity of the i-th satellite
+// into positions[i] and velocities[i]. It returns the number of satellites that couldn't be propagated, whose vectors
+// are zero and whose errors are reported by Err. ErrBatchLength is returned if either slice is shorter than the batch.
+func (b *Batch) PropagateAt(t time.Time, positions, velocities []Vector3) (failed int, err error) {
+	if len(positions) < len(b.sats) || len(velocities) < len(b.sats) {
+		return 0, ErrBatchLength
+	}
+
+	jd, jdFrac := jdayTime(t)
+	for i, k := range b.near {
+		if k == skipped {
+			positions[i], velocities[i] = Vector3{}, Vector3{}
+			continue
+		}
+		tsince := ((jd - b.jdsatepoch[i]) + (jdFrac - b.jdsatepochF[i])) * 1440.0
+		b.tsince[i] = tsince
+
+		var code int
+		var value float64
+		if k == deepSpace {
+			var perr error
+			positions[i], velocities[i], perr = sgp4(&b.sats[i].rec, tsince)
+			if perr != nil {
+				pe := perr.(*PropagationError)
+				code, value = pe.Code, pe.Value
+			}
+		} else {
+			positions[i], velocities[i], code, value = b.propagateNear(k, tsince)
+		}
+
+		b.errCode[i], b.errValue[i] = code, value
+		if code != 0 {
+			failed++
+		}
+	}
+	return failed, nil
+}
+
+// Err returns the *PropagationError of the i-th satellite from the last call to PropagateAt, or nil if it was
+// propagated
+func (b *Batch) Err(i int) error {
+	if b.errCode[i] == 0 {
+		return nil
+	}
+	return newPropagationError(b.errCode[i], b.tsince[i], b.errValue[i])
+}
+
+// propagateNear propagates the near earth satellite k of the columns with the same kernel as sgp4. It returns the
+// error code and offending value instead of an error so that it never allocates.
+func (b *Batch) propagateNear(k int, t float64) (position, velocity Vector3, code int, value float64) {
+	c := nearEarth{
+		whichconst: GravConst{radiusearthkm: b.radiusearthkm[k], xke: b.xke[k], j2: b.j2[k]},
+		bstar:      b.bstar[k],
+		inclo:      b.inclo[k],
+		nodeo:      b.nodeo[k],
+		ecco:       b.ecco[k],
+		argpo:      b.argpo[k],
+		mo:         b.mo[k],
+		no:         b.no[k],
+		con41:      b.con41[k],
+		cc5:        b.cc5[k],
+		d4:         b.d4[k],
+		argpdot:    b.argpdot[k],
+		t4cof:      b.t4cof[k],
+		x7thm1:     b.x7thm1[k],
+		xlcof:      b.xlcof[k],
+		cc1:        b.cc1[k],
+		d2:         b.d2[k],
+		delmo:      b.delmo[k],
+		omgcof:     b.omgcof[k],
+		t2cof:      b.t2cof[k],
+		t5cof:      b.t5cof[k],
+		mdot:       b.mdot[k],
+		xmcof:      b.xmcof[k],
+		aycof:      b.aycof[k],
+		cc4:        b.cc4[k],
+		d3:         b.d3[k],
+		eta:        b.eta[k],
+		sinmao:     b.sinmao[k],
+		t3cof:      b.t3cof[k],
+		x1mth2:     b.x1mth2[k],
+		nodedot:    b.nodedot[k],
+		nodecf:     b.nodecf[k],
+	}
+	if b.simple[k] {
+		c.isimp = 1
+	}
+	return c.propagate(t)
+}
@@ -0,0 +1,175 @@
+package satellite
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+var _ = Describe("Batch", func() {
+	tles := [][2]string{
+		{"1 00005U 58002B   00179.78495062  .00000023  00000-0  28098-4 0  4753", "2 00005  34.2682 348.7242 1859667 331.7664  19.3264 10.82419157413667"},
+		{"1 04632U 70093B   04031.91070959 -.00000084  00000-0  10000-3 0  9955", "2 04632  11.4628 273.1101 1450506 207.6000 143.9350  1.20231981 44145"},
+		{"1 06251U 62025E   06176.82412014  .00008885  00000-0  12808-3 0  3985", "2 06251  58.0579  54.0425 0030035 139.1568 221.1854 15.56387291  6774"},
+		{"1 88888U          80275.98708465  .00073094  13844-3  66816-4 0    8", "2 88888  72.8435 115.9689 0086731  52.6988 110.5714 16.05824518  105"},
+		{"1 24208U 96044A   06177.04061740 -.00000094  00000-0  10000-3 0  1600", "2 24208   3.8536  80.0121 0026640 311.0977  48.3000  1.00778054 36119"},
+		{"1 23599U 95029B   06171.76535463  .00085586  12891-6  12956-2 0  2905", "2 23599   6.9327   0.2849 5782022 274.4436  25.2425  4.47796565123555"},
+		{issLine1, issLine2},
+		{decayingISSLine1, issLine2},
+	}
+	var sats []*Satellite
+	for _, tle := range tles {
+		sat, err := TLEToSat(tle[0], tle[1], "wgs72", SkipChecksum())
+		if err != nil {
+			panic(err)
+		}
+		sats = append(sats, sat)
+	}
+	batch := NewBatch(sats)
+	positions := make([]Vector3, batch.Len())
+	velocities := make([]Vector3, batch.Len())
+
+	It("should give exactly the same results as PropagateAt", func() {
+		for _, t := range []time.Time{
+			time.Date(2006, 6, 27, 12, 0, 0, 0, time.UTC),
+			time.Date(2008, 9, 20, 16, 0, 0, 0, time.UTC),
+			time.Date(2008, 9, 21, 0, 0, 0, 0, time.UTC),
+		} {
+			failed, err := batch.PropagateAt(t, positions, velocities)
+			Expect(err).ToNot(HaveOccurred())
+
+			failures := 0
+			for i, sat := range sats {
+				pos, vel, err := PropagateAt(sat, t)
+				Expect(positions[i]).To(Equal(pos))
+				Expect(velocities[i]).To(Equal(vel))
+				if err != nil {
+					Expect(batch.Err(i)).To(Equal(err))
+					failures++
+				} else {
+					Expect(batch.Err(i)).To(BeNil())
+				}
+			}
+			Expect(failed).To(Equal(failures))
+		}
+		Expect(errors.Is(batch.Err(7), ErrDecayed)).To(BeTrue())
+	})
+
+	It("should not allocate", func() {
+		t := time.Date(2006, 6, 27, 12, 0, 0, 0, time.UTC)
+		allocs := testing.AllocsPerRun(10, func() {
+			batch.PropagateAt(t, positions, velocities)
+		})
+		Expect(allocs).To(BeZero())
+	})
+
+	It("should reject short output slices", func() {
+		_, err := batch.PropagateAt(time.Now(), positions[:1], velocities)
+		Expect(err).To(Equal(ErrBatchLength))
+	})
+
+	It("should match PropagateAt for a catalog of near earth and deep space satellites", func() {
+		catalog := benchmarkCatalog(200)
+		batch := NewBatch(catalog)
+		positions := make([]Vector3, batch.Len())
+		velocities := make([]Vector3, batch.Len())
+		for _, t := range []time.Time{
+			time.Date(2020, 5, 28, 0, 0, 0, 0, time.UTC),
+			time.Date(2020, 6, 4, 0, 0, 0, 0, time.UTC),
+			time.Date(2020, 9, 1, 6, 30, 0, 0, time.UTC),
+		} {
+			_, err := batch.PropagateAt(t, positions, velocities)
+			Expect(err).ToNot(HaveOccurred())
+			for i, sat := range catalog {
+				pos, vel, err := PropagateAt(sat, t)
+				Expect(positions[i]).To(Equal(pos))
+				Expect(velocities[i]).To(Equal(vel))
+				if err != nil {
+					Expect(batch.Err(i)).To(Equal(err))
+				} else {
+					Expect(batch.Err(i)).To(BeNil())
+				}
+			}
+		}
+	})
+
+	It("should skip nil satellites", func() {
+		batch := NewBatch([]*Satellite{nil, sats[0], nil, sats[1]})
+		positions := make([]Vector3, batch.Len())
+		velocities := make([]Vector3, batch.Len())
+		for i := range positions {
+			positions[i] = Vector3{X: 1}
+		}
+		t := time.Date(2006, 6, 27, 12, 0, 0, 0, time.UTC)
+		failed, err := batch.PropagateAt(t, positions, velocities)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(failed).To(BeZero())
+
+		Expect(positions[0]).To(Equal(Vector3{}))
+		Expect(batch.Err(0)).To(BeNil())
+		Expect(positions[2]).To(Equal(Vector3{}))
+		pos, _, _ := PropagateAt(sats[1], t)
+		Expect(positions[3]).To(Equal(pos))
+	})
+})
+
+// benchmarkCatalog returns n satellites spread over low earth orbit, one in ten of them in deep space
+func benchmarkCatalog(n int) []*Satellite {
+	r := rand.New(rand.NewSource(1))
+	epoch := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	sats := make([]*Satellite, n)
+	for i := range sats {
+		meanMotion := 14 + 2*r.Float64()
+		if i%10 == 0 {
+			meanMotion = 1 + r.Float64()
+		}
+		sat, err := NewSatellite(int64(i+1), epoch.Add(time.Duration(r.Int63n(int64(72*time.Hour)))), meanMotion,
+			0.02*r.Float64(), 180*r.Float64(), 360*r.Float64(), 360*r.Float64(), 360*r.Float64(),
+			1e-4*r.Float64(), 0, 0, GravityWGS72)
+		if err != nil {
+			panic(err)
+		}
+		sats[i] = sat
+	}
+	return sats
+}
+
+func BenchmarkPropagateAt(b *testing.B) {
+	sats := benchmarkCatalog(1000)
+	t := time.Date(2020, 6, 4, 0, 0, 0, 0, time.UTC)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, sat := range sats {
+			PropagateAt(sat, t)
+		}
+	}
+}
+
+func BenchmarkPropagate(b *testing.B) {
+	sats := benchmarkCatalog(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, sat := range sats {
+			Propagate(*sat, 2020, 6, 4, 0, 0, 0)
+		}
+	}
+}
+
+func BenchmarkBatchPropagateAt(b *testing.B) {
+	sats := benchmarkCatalog(1000)
+	batch := NewBatch(sats)
+	positions := make([]Vector3, batch.Len())
+	velocities := make([]Vector3, batch.Len())
+	t := time.Date(2020, 6, 4, 0, 0, 0, 0, time.UTC)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		batch.PropagateAt(t, positions, velocities)
+	}
+}
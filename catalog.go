@@ -0,0 +1,79 @@
+package satellite
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// catalogChunk is the number of satellites a worker takes at a time, which keeps the cost of handing out work small
+// next to that of propagating
+const catalogChunk = 64
+
+// CatalogResult is the state of one satellite of a catalog at the requested time, or the error propagating it
+type CatalogResult struct {
+	Position Vector3 // km
+	Velocity Vector3 // km/s
+	// Err is a *PropagationError if the satellite couldn't be propagated, in which case the vectors are zero
+	Err error
+}
+
+// PropagateCatalog propagates every satellite in sats to t across a pool of workers goroutines, or one per CPU if
+// workers is zero or less. Results are keyed by catalog number; should two satellites share a number, the later one
+// in sats wins. Nil satellites are skipped. A satellite that can't be propagated has its error recorded in its result
+// and doesn't affect the others. If ctx is cancelled before every satellite is done, the results so far are returned
+// along with ctx.Err().
+func PropagateCatalog(ctx context.Context, sats []*Satellite, t time.Time,
+	workers int) (map[int64]CatalogResult, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	results := make([]CatalogResult, len(sats))
+	done := make([]bool, len(sats))
+	var next int64
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				end := int(atomic.AddInt64(&next, catalogChunk))
+				start := end - catalogChunk
+				if start >= len(sats) {
+					return
+				}
+				if end > len(sats) {
+					end = len(sats)
+				}
+				for i := start; i < end; i++ {
+					sat := sats[i]
+					if sat == nil {
+						continue
+					}
+					r := &results[i]
+					r.Position, r.Velocity, r.Err = sgp4(&sat.rec, sat.minutesSinceEpoch(t))
+					done[i] = true
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var err error
+	byCatalog := make(map[int64]CatalogResult, len(sats))
+	for i, sat := range sats {
+		if sat == nil {
+			continue
+		}
+		if !done[i] {
+			err = ctx.Err()
+			continue
+		}
+		byCatalog[sat.satnum] = results[i]
+	}
+	return byCatalog, err
+}
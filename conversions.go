@@ -3,37 +3,18 @@ package satellite
 import (
 	"errors"
 	"math"
-)
-
-// this procedure converts the day of the year, epochDays, to the equivalent month day, hour, minute and second.
-func days2mdhms(year int64, epochDays float64) (mon, day, hr, min, sec float64) {
-	lmonth := [12]int{31, 28, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+	"time"
 
-	if year%4 == 0 {
-		lmonth = [12]int{31, 29, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
-	}
+	"github.com/pmcanseco/go-satellite/timescale"
+)
 
+// epochJDay converts a TLE epoch, given as a year and fractional day of the year, into a julian date split into the
+// julian date of the epoch's midnight and the fraction of the day elapsed since then. The fraction is taken straight
+// from epochDays so none of its precision is lost.
+func epochJDay(year int64, epochDays float64) (jd, jdFrac float64) {
 	dayofyr := math.Floor(epochDays)
-
-	i := 1.0
-	inttemp := 0.0
-
-	for dayofyr > inttemp+float64(lmonth[int(i-1)]) && i < 22 {
-		inttemp = inttemp + float64(lmonth[int(i-1)])
-		i += 1
-	}
-
-	mon = i
-	day = dayofyr - inttemp
-
-	temp := (epochDays - dayofyr) * 24.0
-	hr = math.Floor(temp)
-
-	temp = (temp - hr) * 60.0
-	min = math.Floor(temp)
-
-	sec = (temp - min) * 60.0
-
+	jd = JDay(int(year), 1, 0, 0, 0, 0) + dayofyr
+	jdFrac = epochDays - dayofyr
 	return
 }
 
@@ -43,9 +24,35 @@ func JDay(year, mon, day, hr, min, sec int) float64 {
 	return (367.0*float64(year) - math.Floor((7*(float64(year)+math.Floor((float64(mon)+9)/12.0)))*0.25) + math.Floor(275*float64(mon)/9.0) + float64(day) + 1721013.5 + ((float64(sec)/60.0+float64(min))/60.0+float64(hr))/24.0)
 }
 
+// jdayTime calculates the julian date of t split into the julian date of the preceding midnight and the fraction of
+// the day elapsed since then. t is converted to UTC first, and the split keeps nanosecond resolution that a single
+// float64 julian date would lose.
+func jdayTime(t time.Time) (jd, jdFrac float64) {
+	t = t.UTC()
+	year, mon, day := t.Date()
+	jd = JDay(year, int(mon), day, 0, 0, 0)
+	secs := float64(t.Hour()*3600+t.Minute()*60+t.Second()) + float64(t.Nanosecond())/1e9
+	jdFrac = secs / 86400.0
+	return
+}
+
+// jdayToTime is the inverse of jdayTime, turning a julian date split into a midnight and the fraction of the day
+// since then back into a UTC time, rounded to the nanosecond
+func jdayToTime(jd, jdFrac float64) time.Time {
+	// 2440587.5 is the julian date of the unix epoch
+	days := math.Floor(jd - 2440587.5 + 0.5)
+	ns := math.Round((jd - 2440587.5 - days + jdFrac) * 86400e9)
+	return time.Unix(int64(days)*86400, 0).UTC().Add(time.Duration(ns))
+}
+
 // this function finds the greenwich sidereal time (iau-82)
 func gstime(jdut1 float64) (temp float64) {
-	tut1 := (jdut1 - 2451545.0) / 36525.0
+	return gstimeCenturies((jdut1 - 2451545.0) / 36525.0)
+}
+
+// gstimeCenturies finds the greenwich sidereal time (iau-82) at tut1 julian centuries of UT1 since J2000, which
+// callers holding a split julian date can compute without losing the fraction of the day
+func gstimeCenturies(tut1 float64) (temp float64) {
 	temp = -6.2e-6*tut1*tut1*tut1 + 0.093104*tut1*tut1 + (876600.0*3600+8640184.812866)*tut1 + 67310.54841
 	temp = math.Mod((temp * DEG2RAD / 240.0), TWOPI)
 
@@ -75,6 +82,8 @@ func LatLongDeg(rad LatLong) (LatLong, error) {
 
 // Calculate GMST from Julian date.
 // Reference: The 1992 Astronomical Almanac, page B6.
+// jday is read as UT1. A UTC julian date, such as JDay gives, is off by UT1-UTC, up to 0.9 s or 6.6e-5 radians; use
+// GSTimeAt to apply it.
 func ThetaG_JD(jday float64) (ret float64) {
 	_, UT := math.Modf(jday + 0.5)
 	jday = jday - UT
@@ -85,11 +94,37 @@ func ThetaG_JD(jday float64) (ret float64) {
 	return
 }
 
+// ECIToECEF rotates TEME coordinates into the pseudo earth fixed frame by the greenwich sidereal time gmst, in
+// radians. Velocities and polar motion are handled by TEMEToITRF.
+func ECIToECEF(eciCoords Vector3, gmst float64) (ecfCoords Vector3) {
+	m := rotZ(-gmst)
+	return m.apply(eciCoords)
+}
+
+// GSTimeAt returns the greenwich mean sidereal time in radians at the UTC instant utc, evaluated in UT1 with the
+// UT1-UTC eop provides
+func GSTimeAt(utc time.Time, eop EOPProvider) float64 {
+	ut1 := timescale.UTCToUT1(utc, eopAt(eop, utc).DUT1)
+	return gstimeCenturies(julianCenturies(ut1))
+}
+
 // Convert latitude, longitude and altitude(km) into equivalent Earth Centered Intertial coordinates(km)
 // Reference: The 1992 Astronomical Almanac, page K11.
+// jday is read as UT1, as by ThetaG_JD; LLAToECIAt takes a UTC instant.
 func LLAToECI(obsCoords LatLong, alt, jday float64) (eciObs Vector3) {
+	return llaToECI(obsCoords, alt, ThetaG_JD(jday))
+}
+
+// LLAToECIAt converts latitude, longitude and altitude (km) into Earth Centered Inertial coordinates (km) at the UTC
+// instant utc, turning them by the sidereal time in UT1 found with the UT1-UTC eop provides
+func LLAToECIAt(obsCoords LatLong, alt float64, utc time.Time, eop EOPProvider) Vector3 {
+	return llaToECI(obsCoords, alt, GSTimeAt(utc, eop))
+}
+
+// llaToECI converts latitude, longitude and altitude into ECI coordinates at the greenwich sidereal time gmst
+func llaToECI(obsCoords LatLong, alt, gmst float64) (eciObs Vector3) {
 	re := 6378.137
-	theta := math.Mod(ThetaG_JD(jday)+obsCoords.Longitude, TWOPI)
+	theta := math.Mod(gmst+obsCoords.Longitude, TWOPI)
 	r := (re + alt) * math.Cos(obsCoords.Latitude)
 	eciObs.X = r * math.Cos(theta)
 	eciObs.Y = r * math.Sin(theta)
@@ -100,9 +135,21 @@ func LLAToECI(obsCoords LatLong, alt, jday float64) (eciObs Vector3) {
 // Calculate look angles for given satellite position and observer position
 // obsAlt in km
 // Reference: http://celestrak.com/columns/v02n02/
+// jday is read as UT1, as by ThetaG_JD; ECIToLookAnglesAt takes a UTC instant.
 func ECIToLookAngles(eciSat Vector3, obsCoords LatLong, obsAlt, jday float64) (lookAngles LookAngles) {
-	theta := math.Mod(ThetaG_JD(jday)+obsCoords.Longitude, 2*math.Pi)
-	obsPos := LLAToECI(obsCoords, obsAlt, jday)
+	return eciToLookAngles(eciSat, obsCoords, obsAlt, ThetaG_JD(jday))
+}
+
+// ECIToLookAnglesAt calculates the look angles from an observer at obsCoords and obsAlt (km) to a satellite at the
+// ECI position eciSat at the UTC instant utc, using the sidereal time in UT1 found with the UT1-UTC eop provides
+func ECIToLookAnglesAt(eciSat Vector3, obsCoords LatLong, obsAlt float64, utc time.Time, eop EOPProvider) LookAngles {
+	return eciToLookAngles(eciSat, obsCoords, obsAlt, GSTimeAt(utc, eop))
+}
+
+// eciToLookAngles calculates look angles at the greenwich sidereal time gmst
+func eciToLookAngles(eciSat Vector3, obsCoords LatLong, obsAlt, gmst float64) (lookAngles LookAngles) {
+	theta := math.Mod(gmst+obsCoords.Longitude, 2*math.Pi)
+	obsPos := llaToECI(obsCoords, obsAlt, gmst)
 
 	rx := eciSat.X - obsPos.X
 	ry := eciSat.Y - obsPos.Y
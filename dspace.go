@@ -320,7 +320,7 @@ type DpperResult struct {
 }
 
 // this procedure provides deep space long period periodic contributions to the mean elements. by design, these periodics are zero at epoch. this used to be dscom which included initialization, but it's really a recurring function.
-func dpper(satrec *Satellite, inclo float64, init string, ep, inclp, nodep, argpp, mp float64, opsmode string) (result DpperResult) {
+func dpper(satrec *elsetrec, t, inclo float64, init string, ep, inclp, nodep, argpp, mp float64, opsmode string) (result DpperResult) {
 	e3 := satrec.e3
 	ee2 := satrec.ee2
 	peo := satrec.peo
@@ -340,7 +340,6 @@ func dpper(satrec *Satellite, inclo float64, init string, ep, inclp, nodep, argp
 	sl2 := satrec.sl2
 	sl3 := satrec.sl3
 	sl4 := satrec.sl4
-	t := satrec.t
 	xgh2 := satrec.xgh2
 	xgh3 := satrec.xgh3
 	xgh4 := satrec.xgh4
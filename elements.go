@@ -0,0 +1,152 @@
+package satellite
+
+import (
+	"fmt"
+	"time"
+)
+
+// ElementSet holds every field of a two line element set, in the units the TLE format uses
+type ElementSet struct {
+	// CatalogNumber is the satellite's NORAD catalog number
+	CatalogNumber int64
+	// Classification is U for unclassified, C for classified or S for secret
+	Classification byte
+	// IntlDesignator is the international designator: the last two digits of the launch year, the launch number of
+	// the year and the piece of the launch, such as 98067A. It is empty for objects without one.
+	IntlDesignator string
+	// EpochYear is the last two digits of the epoch's year, where 57 to 99 stand for 1957 to 1999 and 00 to 56 for
+	// 2000 to 2056
+	EpochYear int64
+	// EpochDay is the epoch's day of the year and fraction of the day, January 1 00:00 UTC being 1.0
+	EpochDay float64
+	// Epoch is the epoch in UTC, the instant the satellite is propagated from as returned by Satellite.Epoch. That of a
+	// TLE may be a few nanoseconds off the decimal epoch day, which a float64 doesn't hold exactly.
+	Epoch time.Time
+	// MeanMotionDot is the first derivative of the mean motion divided by 2, in revolutions per day squared
+	MeanMotionDot float64
+	// MeanMotionDDot is the second derivative of the mean motion divided by 6, in revolutions per day cubed
+	MeanMotionDDot float64
+	// BStar is the SGP4 drag term in inverse earth radii
+	BStar float64
+	// EphemerisType is the model the element set was generated for, 0 for SGP4 element sets
+	EphemerisType int64
+	// ElementSetNumber is incremented each time a new element set is published for the object
+	ElementSetNumber int64
+	// Inclination is in degrees
+	Inclination float64
+	// RightAscension is the right ascension of the ascending node in degrees
+	RightAscension float64
+	// Eccentricity is the mean eccentricity, between 0 and 1
+	Eccentricity float64
+	// ArgPerigee is the argument of perigee in degrees
+	ArgPerigee float64
+	// MeanAnomaly is in degrees
+	MeanAnomaly float64
+	// MeanMotion is the Kozai mean motion in revolutions per day
+	MeanMotion float64
+	// RevNumber is the number of revolutions completed at epoch
+	RevNumber int64
+}
+
+// Elements returns a copy of the element set sat was created from
+func (sat *Satellite) Elements() ElementSet {
+	return ElementSet{
+		CatalogNumber:    sat.satnum,
+		Classification:   sat.classification,
+		IntlDesignator:   sat.intldesg,
+		EpochYear:        sat.epochyr,
+		EpochDay:         sat.epochdays,
+		Epoch:            sat.Epoch(),
+		MeanMotionDot:    sat.ndot,
+		MeanMotionDDot:   sat.nddot,
+		BStar:            sat.bstar,
+		EphemerisType:    sat.ephtype,
+		ElementSetNumber: sat.elnum,
+		Inclination:      sat.inclo,
+		RightAscension:   sat.nodeo,
+		Eccentricity:     sat.ecco,
+		ArgPerigee:       sat.argpo,
+		MeanAnomaly:      sat.mo,
+		MeanMotion:       sat.no,
+		RevNumber:        sat.revnum,
+	}
+}
+
+// NewSatellite initializes a Satellite straight from SGP4 mean elements, in the units of the TLE format: mean motion
+// in revolutions per day, angles in degrees, B* in inverse earth radii, ndot (the first derivative of mean motion
+// divided by 2) in revolutions per day squared and nddot (the second derivative divided by 6) in revolutions per day
+// cubed. The epoch keeps its full precision. The satellite has no TLE lines, and catalog numbers aren't limited to
+// those a TLE can hold.
+func NewSatellite(catalogNumber int64, epoch time.Time, meanMotion, eccentricity, inclination, raan, argPerigee,
+	meanAnomaly, bstar, ndot, nddot float64, gravConst Gravity, opts ...Option) (*Satellite, error) {
+	return satelliteFromElements(ElementSet{
+		CatalogNumber:  catalogNumber,
+		Epoch:          epoch,
+		MeanMotionDot:  ndot,
+		MeanMotionDDot: nddot,
+		BStar:          bstar,
+		Inclination:    inclination,
+		RightAscension: raan,
+		Eccentricity:   eccentricity,
+		ArgPerigee:     argPerigee,
+		MeanAnomaly:    meanAnomaly,
+		MeanMotion:     meanMotion,
+	}, gravConst, opts...)
+}
+
+// satelliteFromElements initializes a Satellite from an element set. The epoch is taken from e.Epoch alone, at its
+// full precision; EpochYear and EpochDay are ignored. Line1 and Line2 are left empty. An eccentricity outside [0, 1)
+// gives ErrEccentricity and a mean motion that isn't positive ErrMeanMotion.
+func satelliteFromElements(e ElementSet, gravConst Gravity, opts ...Option) (*Satellite, error) {
+	if !(e.Eccentricity >= 0 && e.Eccentricity < 1) {
+		return nil, fmt.Errorf("%w: %v", ErrEccentricity, e.Eccentricity)
+	}
+	if !(e.MeanMotion > 0) {
+		return nil, fmt.Errorf("%w: %v", ErrMeanMotion, e.MeanMotion)
+	}
+
+	whichconst, err := getGravConst(gravConst)
+	if err != nil {
+		return nil, err
+	}
+
+	epoch := e.Epoch.UTC()
+	jd, jdFrac := jdayTime(epoch)
+	midnight := time.Date(epoch.Year(), epoch.Month(), epoch.Day(), 0, 0, 0, 0, time.UTC)
+
+	sat := &Satellite{
+		satnum:         e.CatalogNumber,
+		classification: e.Classification,
+		intldesg:       e.IntlDesignator,
+		ephtype:        e.EphemerisType,
+		elnum:          e.ElementSetNumber,
+		revnum:         e.RevNumber,
+		whichconst:     whichconst,
+		epochyr:        int64(epoch.Year() % 100),
+		epochdays:      float64(epoch.YearDay()) + float64(epoch.Sub(midnight))/float64(24*time.Hour),
+		ndot:           e.MeanMotionDot,
+		nddot:          e.MeanMotionDDot,
+		bstar:          e.BStar,
+		inclo:          e.Inclination,
+		nodeo:          e.RightAscension,
+		ecco:           e.Eccentricity,
+		argpo:          e.ArgPerigee,
+		mo:             e.MeanAnomaly,
+		no:             e.MeanMotion,
+	}
+	if sat.classification == 0 {
+		sat.classification = 'U'
+	}
+	if err := sat.initialize(jd, jdFrac, newOptions(opts)); err != nil {
+		return nil, err
+	}
+	return sat, nil
+}
+
+// epochFullYear expands the two digit year of a TLE epoch
+func epochFullYear(epochyr int64) int64 {
+	if epochyr < 57 {
+		return epochyr + 2000
+	}
+	return epochyr + 1900
+}
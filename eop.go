@@ -0,0 +1,347 @@
+package satellite
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors returned by the earth orientation parameter parsers
+var (
+	ErrEOPEmpty  = errors.New("no earth orientation parameters found")
+	ErrEOPColumn = errors.New("required column is missing")
+	ErrEOPOrder  = errors.New("dates are not in increasing order")
+)
+
+// EOP holds the Earth orientation parameters used when converting between frames. The zero value applies none.
+type EOP struct {
+	// DUT1 is UT1-UTC in seconds
+	DUT1 float64
+	// XP and YP are the coordinates of the celestial intermediate pole in the ITRF, in radians
+	XP, YP float64
+	// LOD is the excess length of day in seconds, which slows the earth's rotation rate
+	LOD float64
+	// DPsi and DEps are the IERS corrections to the IAU-1980 nutation in longitude and obliquity, in radians. With
+	// them the J2000 frame produced by precession and nutation becomes a close approximation of GCRF.
+	DPsi, DEps float64
+	// DX and DY are the IERS celestial pole offsets with respect to the IAU-2000A model, in radians. The IAU-76/FK5
+	// conversions in this package don't use them.
+	DX, DY float64
+}
+
+// EOPAt returns e whatever the time, so that a single set of parameters can be passed wherever an EOPProvider is
+// taken
+func (e EOP) EOPAt(t time.Time) EOP {
+	return e
+}
+
+// EOPProvider supplies the Earth orientation parameters in effect at a UTC instant
+type EOPProvider interface {
+	EOPAt(t time.Time) EOP
+}
+
+// eopAt returns the parameters p provides at t, or none if p is nil
+func eopAt(p EOPProvider, t time.Time) EOP {
+	if p == nil {
+		return EOP{}
+	}
+	return p.EOPAt(t)
+}
+
+// EOPTable holds daily Earth orientation parameters, such as those published by the IERS, and interpolates between
+// them
+type EOPTable struct {
+	mjd []float64
+	eop []EOP
+}
+
+// NewEOPTable returns a table of the parameters eop, each in effect at the matching UTC instant of times. times must
+// be in increasing order.
+func NewEOPTable(times []time.Time, eop []EOP) (*EOPTable, error) {
+	if len(times) == 0 || len(times) != len(eop) {
+		return nil, ErrEOPEmpty
+	}
+	table := &EOPTable{mjd: make([]float64, len(times)), eop: make([]EOP, len(eop))}
+	for i, t := range times {
+		table.mjd[i] = modifiedJulianDate(t)
+		if i > 0 && table.mjd[i] <= table.mjd[i-1] {
+			return nil, ErrEOPOrder
+		}
+	}
+	copy(table.eop, eop)
+	return table, nil
+}
+
+// modifiedJulianDate returns the modified julian date of t read as UTC
+func modifiedJulianDate(t time.Time) float64 {
+	jd, jdFrac := jdayTime(t)
+	return (jd - 2400000.5) + jdFrac
+}
+
+// Len returns the number of days in the table
+func (t *EOPTable) Len() int {
+	return len(t.mjd)
+}
+
+// Span returns the first and last instants the table covers, or zero times if it is empty
+func (t *EOPTable) Span() (first, last time.Time) {
+	if len(t.mjd) == 0 {
+		return time.Time{}, time.Time{}
+	}
+	return mjdToTime(t.mjd[0]), mjdToTime(t.mjd[len(t.mjd)-1])
+}
+
+// mjdToTime returns the UTC instant of the modified julian date mjd
+func mjdToTime(mjd float64) time.Time {
+	return jdayToTime(2400000.5, mjd)
+}
+
+// EOPAt linearly interpolates the parameters at the UTC instant t. UT1-UTC is interpolated across the leap second
+// it jumps by. Outside the span of the table no parameters are applied and the zero EOP is returned.
+func (t *EOPTable) EOPAt(at time.Time) EOP {
+	mjd := modifiedJulianDate(at)
+	n := len(t.mjd)
+	if n == 0 || mjd < t.mjd[0] || mjd > t.mjd[n-1] {
+		return EOP{}
+	}
+	i := sort.SearchFloat64s(t.mjd, mjd)
+	if t.mjd[i] == mjd {
+		return t.eop[i]
+	}
+
+	e0, e1 := &t.eop[i-1], &t.eop[i]
+	f := (mjd - t.mjd[i-1]) / (t.mjd[i] - t.mjd[i-1])
+	lerp := func(a, b float64) float64 {
+		return a + (b-a)*f
+	}
+	dut1 := e1.DUT1
+	if dut1-e0.DUT1 > 0.5 {
+		dut1 -= 1.0
+	} else if dut1-e0.DUT1 < -0.5 {
+		dut1 += 1.0
+	}
+	return EOP{
+		DUT1: lerp(e0.DUT1, dut1),
+		XP:   lerp(e0.XP, e1.XP),
+		YP:   lerp(e0.YP, e1.YP),
+		LOD:  lerp(e0.LOD, e1.LOD),
+		DPsi: lerp(e0.DPsi, e1.DPsi),
+		DEps: lerp(e0.DEps, e1.DEps),
+		DX:   lerp(e0.DX, e1.DX),
+		DY:   lerp(e0.DY, e1.DY),
+	}
+}
+
+// add appends a day to the table
+func (t *EOPTable) add(mjd float64, eop EOP) error {
+	if n := len(t.mjd); n > 0 && mjd <= t.mjd[n-1] {
+		return ErrEOPOrder
+	}
+	t.mjd = append(t.mjd, mjd)
+	t.eop = append(t.eop, eop)
+	return nil
+}
+
+// Units of the IERS files
+const (
+	masToRad = arcsecToRad / 1000.0
+	msToSec  = 1.0 / 1000.0
+)
+
+// ParseFinals2000A reads the fixed width IERS finals2000A files, such as finals2000A.all and finals2000A.data. The
+// Bulletin A values are used, as they cover the predictions too. Days without a UT1-UTC value, found at the end of
+// the files, are skipped. A line that can't be parsed is reported as a *RecordError.
+func ParseFinals2000A(r io.Reader) (*EOPTable, error) {
+	return parseFinals(r, false)
+}
+
+// ParseFinals reads the fixed width IERS finals files for the IAU-1980 nutation theory, such as finals.all and
+// finals.data, whose nutation columns hold the dPsi and dEps used by TEMEToGCRF. It otherwise behaves like
+// ParseFinals2000A.
+func ParseFinals(r io.Reader) (*EOPTable, error) {
+	return parseFinals(r, true)
+}
+
+// parseFinals reads a fixed width finals file whose nutation columns are dPsi and dEps if iau1980 is set, and dX and
+// dY otherwise
+func parseFinals(r io.Reader, iau1980 bool) (*EOPTable, error) {
+	table := &EOPTable{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if strings.TrimSpace(finalsColumn(text, 59, 68)) == "" {
+			continue
+		}
+
+		var mjd float64
+		var eop EOP
+		var nut1, nut2 float64
+		fields := []struct {
+			from, to int
+			value    *float64
+			required bool
+		}{
+			{8, 15, &mjd, true},
+			{19, 27, &eop.XP, true},
+			{38, 46, &eop.YP, true},
+			{59, 68, &eop.DUT1, true},
+			{80, 86, &eop.LOD, false},
+			{98, 106, &nut1, false},
+			{117, 125, &nut2, false},
+		}
+		for _, field := range fields {
+			s := strings.TrimSpace(finalsColumn(text, field.from, field.to))
+			if s == "" && !field.required {
+				continue
+			}
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, &RecordError{Line: line, Err: fmt.Errorf("columns %d-%d: %w", field.from, field.to, err)}
+			}
+			*field.value = v
+		}
+		eop.finalsUnits(nut1, nut2, iau1980)
+
+		if err := table.add(mjd, eop); err != nil {
+			return nil, &RecordError{Line: line, Err: err}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if table.Len() == 0 {
+		return nil, ErrEOPEmpty
+	}
+	return table, nil
+}
+
+// finalsColumn returns columns from to to, counted from 1 and inclusive, of line, or what there is of them
+func finalsColumn(line string, from, to int) string {
+	if from > len(line) {
+		return ""
+	}
+	if to > len(line) {
+		to = len(line)
+	}
+	return line[from-1 : to]
+}
+
+// finalsUnits converts the pole coordinates of e from arcseconds and its length of day from milliseconds, as the IERS
+// files give them, and sets its nutation corrections from nut1 and nut2 in milliarcseconds
+func (e *EOP) finalsUnits(nut1, nut2 float64, iau1980 bool) {
+	e.XP *= arcsecToRad
+	e.YP *= arcsecToRad
+	e.LOD *= msToSec
+	if iau1980 {
+		e.DPsi, e.DEps = nut1*masToRad, nut2*masToRad
+	} else {
+		e.DX, e.DY = nut1*masToRad, nut2*masToRad
+	}
+}
+
+// ParseFinalsCSV reads the semicolon separated versions of the IERS finals files, such as finals2000A.all.csv and
+// finals.all.csv. Columns are found by their header, the nutation corrections being read as dX and dY or as dPsi and
+// dEpsilon depending on which the file has. Only the first, Bulletin A, column of each name is used. Rows without a
+// UT1-UTC value are skipped. A row that can't be parsed is reported as a *RecordError whose Line is its row number,
+// counting the header as row 1.
+func ParseFinalsCSV(r io.Reader) (*EOPTable, error) {
+	c := csv.NewReader(r)
+	c.Comma = ';'
+	c.FieldsPerRecord = -1
+	c.ReuseRecord = true
+
+	header, err := c.Read()
+	if err == io.EOF {
+		return nil, ErrEOPEmpty
+	} else if err != nil {
+		return nil, err
+	}
+	// the header is overwritten by the next read
+	header = append([]string(nil), header...)
+	columns := map[string]int{}
+	for i, h := range header {
+		name := strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+	for _, name := range []string{"MJD", "X_POLE", "Y_POLE", "UT1-UTC"} {
+		if _, ok := columns[name]; !ok {
+			return nil, &RecordError{Line: 1, Err: fmt.Errorf("%s: %w", name, ErrEOPColumn)}
+		}
+	}
+	_, iau1980 := columns["DPSI"]
+	nutation := [2]string{"DX", "DY"}
+	if iau1980 {
+		nutation = [2]string{"DPSI", "DEPSILON"}
+	}
+
+	table := &EOPTable{}
+	for row := 2; ; row++ {
+		record, err := c.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, &RecordError{Line: row, Err: err}
+			}
+			return nil, err
+		}
+		value := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		if value("UT1-UTC") == "" {
+			continue
+		}
+
+		var mjd float64
+		var eop EOP
+		var nut1, nut2 float64
+		fields := []struct {
+			name     string
+			value    *float64
+			required bool
+		}{
+			{"MJD", &mjd, true},
+			{"X_POLE", &eop.XP, true},
+			{"Y_POLE", &eop.YP, true},
+			{"UT1-UTC", &eop.DUT1, true},
+			{"LOD", &eop.LOD, false},
+			{nutation[0], &nut1, false},
+			{nutation[1], &nut2, false},
+		}
+		for _, field := range fields {
+			s := value(field.name)
+			if s == "" && !field.required {
+				continue
+			}
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, &RecordError{Line: row, Err: fmt.Errorf("%s: %w", header[columns[field.name]], err)}
+			}
+			*field.value = v
+		}
+		eop.finalsUnits(nut1, nut2, iau1980)
+
+		if err := table.add(mjd, eop); err != nil {
+			return nil, &RecordError{Line: row, Err: err}
+		}
+	}
+	if table.Len() == 0 {
+		return nil, ErrEOPEmpty
+	}
+	return table, nil
+}
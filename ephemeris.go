@@ -0,0 +1,79 @@
+package satellite
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// State is the position and velocity of a satellite at an instant, in the TEME frame
+type State struct {
+	Time     time.Time
+	Position Vector3 // km
+	Velocity Vector3 // km/s
+}
+
+// ErrEphemerisStep is returned for a step that is zero, leads away from the end of the span or would take more than
+// MaxEphemerisStates states to cover it
+var ErrEphemerisStep = errors.New("step does not lead from start to end or gives too many states")
+
+// MaxEphemerisStates is the largest number of states Ephemeris produces, about 700 MB of them, which keeps a step far
+// too small for its span from allocating the memory for the states before any is propagated
+const MaxEphemerisStates = 10000000
+
+// EphemerisError reports the point at which an ephemeris stopped. The states before Index were propagated and are
+// returned along with the error.
+type EphemerisError struct {
+	// Index is the position in the ephemeris of the state that couldn't be propagated
+	Index int
+	// Time is the instant that couldn't be propagated to
+	Time time.Time
+	// Err is the underlying error, usually a *PropagationError
+	Err error
+}
+
+func (e *EphemerisError) Error() string {
+	return fmt.Sprintf("ephemeris stopped at state %d (%s): %v", e.Index, e.Time.Format(time.RFC3339Nano), e.Err)
+}
+
+func (e *EphemerisError) Unwrap() error {
+	return e.Err
+}
+
+// Ephemeris propagates sat from start to end every step, both ends included. step may be negative to go back in time
+// when end is before start. A step that would give more than MaxEphemerisStates states is rejected with
+// ErrEphemerisStep. If the satellite can't be propagated to one of the instants, such as once it has decayed, the
+// states up to that point are returned with an *EphemerisError.
+func Ephemeris(sat *Satellite, start, end time.Time, step time.Duration) ([]State, error) {
+	span := end.Sub(start)
+	if step == 0 || (span > 0 && step < 0) || (span < 0 && step > 0) || span/step >= MaxEphemerisStates {
+		return nil, fmt.Errorf("%w: %v from %v to %v", ErrEphemerisStep, step, start, end)
+	}
+
+	n := int(span/step) + 1
+	states := make([]State, 0, n)
+	tsince := sat.minutesSinceEpoch(start)
+	for i := 0; i < n; i++ {
+		t := start.Add(time.Duration(i) * step)
+		pos, vel, err := sgp4(&sat.rec, tsince+float64(time.Duration(i)*step)/float64(time.Minute))
+		if err != nil {
+			return states, &EphemerisError{Index: i, Time: t, Err: err}
+		}
+		states = append(states, State{Time: t, Position: pos, Velocity: vel})
+	}
+	return states, nil
+}
+
+// EphemerisAt propagates sat to each of times, in order. If the satellite can't be propagated to one of them, such as
+// once it has decayed, the states up to that point are returned with an *EphemerisError.
+func EphemerisAt(sat *Satellite, times []time.Time) ([]State, error) {
+	states := make([]State, 0, len(times))
+	for i, t := range times {
+		pos, vel, err := sgp4(&sat.rec, sat.minutesSinceEpoch(t))
+		if err != nil {
+			return states, &EphemerisError{Index: i, Time: t, Err: err}
+		}
+		states = append(states, State{Time: t, Position: pos, Velocity: vel})
+	}
+	return states, nil
+}
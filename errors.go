@@ -0,0 +1,54 @@
+package satellite
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors reported when a satellite can't be propagated, one for each error code of the reference SGP4 implementation.
+// The error returned by propagation is a *PropagationError wrapping one of these, so test for them with errors.Is.
+// Code 5, for epoch elements whose perigee is below the earth's surface, isn't reported: like the reference
+// implementation, satellites are propagated until they are actually below the surface, which gives ErrDecayed.
+var (
+	ErrEccentricity          = errors.New("mean eccentricity not within range 0.0 <= e < 1.0")       // code 1
+	ErrMeanMotion            = errors.New("mean motion is not positive")                             // code 2
+	ErrPerturbedEccentricity = errors.New("perturbed eccentricity not within range 0.0 <= e <= 1.0") // code 3
+	ErrSemiLatusRectum       = errors.New("semilatus rectum is less than zero")                      // code 4
+	ErrDecayed               = errors.New("satellite has decayed")                                   // code 6
+)
+
+// Sentinel errors indexed by their reference error code
+var propagationErrors = [...]error{
+	1: ErrEccentricity,
+	2: ErrMeanMotion,
+	3: ErrPerturbedEccentricity,
+	4: ErrSemiLatusRectum,
+	6: ErrDecayed,
+}
+
+// PropagationError holds the details of a failed propagation
+type PropagationError struct {
+	// Code is the error code of the reference SGP4 implementation, 1 through 4 or 6
+	Code int
+	// Tsince is the time of the failed propagation in minutes since the satellite's epoch
+	Tsince float64
+	// Value is the offending quantity: the eccentricity, mean motion, semilatus rectum or radius (in earth radii)
+	// that was out of range
+	Value float64
+}
+
+func newPropagationError(code int, tsince, value float64) *PropagationError {
+	return &PropagationError{Code: code, Tsince: tsince, Value: value}
+}
+
+func (e *PropagationError) Error() string {
+	return fmt.Sprintf("sgp4 error %d at %f minutes since epoch: %v (%g)", e.Code, e.Tsince, e.Unwrap(), e.Value)
+}
+
+// Unwrap returns the sentinel error for e's code
+func (e *PropagationError) Unwrap() error {
+	if e.Code < 1 || e.Code >= len(propagationErrors) {
+		return nil
+	}
+	return propagationErrors[e.Code]
+}
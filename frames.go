@@ -0,0 +1,286 @@
+package satellite
+
+import (
+	"math"
+	"time"
+
+	"github.com/pmcanseco/go-satellite/timescale"
+)
+
+// Frames
+//
+// sgp4 returns positions and velocities in TEME, the True Equator Mean Equinox frame of date. The functions here
+// rotate TEME vectors into the Mean Of Date frame (MOD) by undoing nutation, and from MOD into J2000 by undoing
+// IAU-76/FK5 precession, following Vallado's "Revisiting Spacetrack Report #3" (AIAA 2006-6753). The rotations are
+// slow enough that velocities are rotated with the same matrix as positions.
+//
+// For the earth fixed frames TEME is turned by the greenwich mean sidereal time into the Pseudo Earth Fixed frame
+// (PEF), where velocities also lose the earth's rotation, and PEF is turned by polar motion into ITRF.
+//
+// Every conversion has the signature (position, velocity Vector3, utc time.Time[, eop EOPProvider]) (Vector3,
+// Vector3): it takes the UTC instant of the vectors, and an EOPProvider if earth orientation enters into it, nil
+// applying no corrections. Precession and nutation are evaluated in Terrestrial Time, found with the leap second table
+// of the timescale package, and sidereal time in UT1, found with the UT1-UTC of the EOPProvider.
+
+// arcsecToRad converts arcseconds to radians
+const arcsecToRad = DEG2RAD / 3600.0
+
+// earthRotationRate is the earth's mean angular velocity in rad/s with respect to the precessing equinox
+const earthRotationRate = 7.29211514670698e-05
+
+// matrix3 is a 3x3 rotation matrix stored by rows
+type matrix3 [3][3]float64
+
+// apply returns m times v
+func (m *matrix3) apply(v Vector3) Vector3 {
+	return Vector3{
+		X: m[0][0]*v.X + m[0][1]*v.Y + m[0][2]*v.Z,
+		Y: m[1][0]*v.X + m[1][1]*v.Y + m[1][2]*v.Z,
+		Z: m[2][0]*v.X + m[2][1]*v.Y + m[2][2]*v.Z,
+	}
+}
+
+// applyT returns the transpose of m times v, which undoes the rotation m applies
+func (m *matrix3) applyT(v Vector3) Vector3 {
+	return Vector3{
+		X: m[0][0]*v.X + m[1][0]*v.Y + m[2][0]*v.Z,
+		Y: m[0][1]*v.X + m[1][1]*v.Y + m[2][1]*v.Z,
+		Z: m[0][2]*v.X + m[1][2]*v.Y + m[2][2]*v.Z,
+	}
+}
+
+// mul returns the product m times n
+func (m *matrix3) mul(n *matrix3) (p matrix3) {
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			p[i][j] = m[i][0]*n[0][j] + m[i][1]*n[1][j] + m[i][2]*n[2][j]
+		}
+	}
+	return
+}
+
+// rotZ returns the matrix that rotates vectors by angle radians about the z axis
+func rotZ(angle float64) matrix3 {
+	s, c := math.Sincos(angle)
+	return matrix3{{c, -s, 0}, {s, c, 0}, {0, 0, 1}}
+}
+
+// julianCenturies returns the number of julian centuries between J2000 and t, reading t's clock in whichever time
+// scale the caller passes it in
+func julianCenturies(t time.Time) float64 {
+	jd, jdFrac := jdayTime(t)
+	return ((jd - 2451545.0) + jdFrac) / 36525.0
+}
+
+// precession returns the IAU-76 precession matrix rotating MOD vectors at ttt julian centuries of TT into J2000
+func precession(ttt float64) matrix3 {
+	ttt2 := ttt * ttt
+	ttt3 := ttt2 * ttt
+	zeta := (2306.2181*ttt + 0.30188*ttt2 + 0.017998*ttt3) * arcsecToRad
+	theta := (2004.3109*ttt - 0.42665*ttt2 - 0.041833*ttt3) * arcsecToRad
+	z := (2306.2181*ttt + 1.09468*ttt2 + 0.018203*ttt3) * arcsecToRad
+
+	sinzeta, coszeta := math.Sincos(zeta)
+	sintheta, costheta := math.Sincos(theta)
+	sinz, cosz := math.Sincos(z)
+	return matrix3{
+		{coszeta*costheta*cosz - sinzeta*sinz, coszeta*costheta*sinz + sinzeta*cosz, coszeta * sintheta},
+		{-sinzeta*costheta*cosz - coszeta*sinz, -sinzeta*costheta*sinz + coszeta*cosz, -sinzeta * sintheta},
+		{-sintheta * cosz, -sintheta * sinz, costheta},
+	}
+}
+
+// nutation evaluates the IAU-1980 nutation at ttt julian centuries of TT, adding the corrections in eop. It returns
+// the nutation in longitude, the mean obliquity and the longitude of the moon's ascending node, all in radians, and
+// the matrix rotating TOD vectors into MOD.
+func nutation(ttt float64, eop EOP) (deltaPsi, meanEps, omega float64, nut matrix3) {
+	ttt2 := ttt * ttt
+	ttt3 := ttt2 * ttt
+
+	meanEps = (84381.448 - 46.8150*ttt - 0.00059*ttt2 + 0.001813*ttt3) * arcsecToRad
+
+	// Delaunay arguments of the IAU-1980 theory
+	l := ((0.064*ttt+31.310)*ttt+1717915922.6330)*ttt/3600.0 + 134.96298139
+	l1 := ((-0.012*ttt-0.577)*ttt+129596581.2240)*ttt/3600.0 + 357.52772333
+	f := ((0.011*ttt-13.257)*ttt+1739527263.1370)*ttt/3600.0 + 93.27191028
+	d := ((0.019*ttt-6.891)*ttt+1602961601.3280)*ttt/3600.0 + 297.85036306
+	omega = ((0.008*ttt+7.455)*ttt-6962890.5390)*ttt/3600.0 + 125.04452222
+	l = math.Mod(l, 360.0) * DEG2RAD
+	l1 = math.Mod(l1, 360.0) * DEG2RAD
+	f = math.Mod(f, 360.0) * DEG2RAD
+	d = math.Mod(d, 360.0) * DEG2RAD
+	omega = math.Mod(omega, 360.0) * DEG2RAD
+
+	// sum the smallest terms first
+	var deltaEps float64
+	for i := len(iau1980Nutation) - 1; i >= 0; i-- {
+		term := &iau1980Nutation[i]
+		arg := term[0]*l + term[1]*l1 + term[2]*f + term[3]*d + term[4]*omega
+		sinArg, cosArg := math.Sincos(arg)
+		deltaPsi += (term[5] + term[6]*ttt) * sinArg
+		deltaEps += (term[7] + term[8]*ttt) * cosArg
+	}
+	deltaPsi = deltaPsi*1e-4*arcsecToRad + eop.DPsi
+	deltaEps = deltaEps*1e-4*arcsecToRad + eop.DEps
+	trueEps := meanEps + deltaEps
+
+	sinpsi, cospsi := math.Sincos(deltaPsi)
+	sineps, coseps := math.Sincos(meanEps)
+	sintrue, costrue := math.Sincos(trueEps)
+	nut = matrix3{
+		{cospsi, costrue * sinpsi, sintrue * sinpsi},
+		{-coseps * sinpsi, costrue*coseps*cospsi + sintrue*sineps, sintrue*coseps*cospsi - sineps*costrue},
+		{-sineps * sinpsi, costrue*sineps*cospsi - sintrue*coseps, sintrue*sineps*cospsi + costrue*coseps},
+	}
+	return
+}
+
+// kinematicEqeStart is 1997 February 27, in julian centuries of TT since J2000, from when the IAU-1994 resolution adds
+// the kinematic terms to the equation of the equinoxes
+const kinematicEqeStart = (2450449.5 - 2451545.0) / 36525.0
+
+// temeToMOD returns the matrix rotating TEME vectors at ttt julian centuries of TT into MOD. TEME is the true of date
+// frame turned about its pole by the equation of the equinoxes.
+func temeToMOD(ttt float64, eop EOP) matrix3 {
+	deltaPsi, meanEps, omega, nut := nutation(ttt, eop)
+	eqe := deltaPsi * math.Cos(meanEps)
+	if ttt > kinematicEqeStart {
+		eqe += (0.00264*math.Sin(omega) + 0.000063*math.Sin(2.0*omega)) * arcsecToRad
+	}
+	rot := rotZ(math.Mod(eqe, TWOPI))
+	return nut.mul(&rot)
+}
+
+// ttCenturies returns the number of julian centuries of TT between J2000 and the UTC instant utc
+func ttCenturies(utc time.Time) float64 {
+	return julianCenturies(timescale.UTCToTT(utc))
+}
+
+// TEMEToMOD rotates a TEME position (km) and velocity (km/s) at the instant utc into the mean of date frame, applying
+// the nutation corrections eop provides
+func TEMEToMOD(position, velocity Vector3, utc time.Time, eop EOPProvider) (Vector3, Vector3) {
+	m := temeToMOD(ttCenturies(utc), eopAt(eop, utc))
+	return m.apply(position), m.apply(velocity)
+}
+
+// MODToTEME is the inverse of TEMEToMOD
+func MODToTEME(position, velocity Vector3, utc time.Time, eop EOPProvider) (Vector3, Vector3) {
+	m := temeToMOD(ttCenturies(utc), eopAt(eop, utc))
+	return m.applyT(position), m.applyT(velocity)
+}
+
+// MODToJ2000 rotates a mean of date position (km) and velocity (km/s) at the instant utc into J2000 by removing IAU-76
+// precession
+func MODToJ2000(position, velocity Vector3, utc time.Time) (Vector3, Vector3) {
+	m := precession(ttCenturies(utc))
+	return m.apply(position), m.apply(velocity)
+}
+
+// J2000ToMOD is the inverse of MODToJ2000
+func J2000ToMOD(position, velocity Vector3, utc time.Time) (Vector3, Vector3) {
+	m := precession(ttCenturies(utc))
+	return m.applyT(position), m.applyT(velocity)
+}
+
+// temeToGCRF returns the matrix rotating TEME vectors at ttt julian centuries of TT into J2000, or GCRF when eop holds
+// nutation corrections
+func temeToGCRF(ttt float64, eop EOP) matrix3 {
+	prec := precession(ttt)
+	m := temeToMOD(ttt, eop)
+	return prec.mul(&m)
+}
+
+// TEMEToJ2000 rotates a TEME position (km) and velocity (km/s) at the instant utc into the J2000 frame through
+// precession and nutation alone
+func TEMEToJ2000(position, velocity Vector3, utc time.Time) (Vector3, Vector3) {
+	return TEMEToGCRF(position, velocity, utc, nil)
+}
+
+// J2000ToTEME is the inverse of TEMEToJ2000
+func J2000ToTEME(position, velocity Vector3, utc time.Time) (Vector3, Vector3) {
+	return GCRFToTEME(position, velocity, utc, nil)
+}
+
+// TEMEToGCRF rotates a TEME position (km) and velocity (km/s) at the instant utc into GCRF, applying the IERS nutation
+// corrections eop provides to the IAU-76/FK5 reduction. Without corrections the result is J2000.
+func TEMEToGCRF(position, velocity Vector3, utc time.Time, eop EOPProvider) (Vector3, Vector3) {
+	m := temeToGCRF(ttCenturies(utc), eopAt(eop, utc))
+	return m.apply(position), m.apply(velocity)
+}
+
+// GCRFToTEME is the inverse of TEMEToGCRF
+func GCRFToTEME(position, velocity Vector3, utc time.Time, eop EOPProvider) (Vector3, Vector3) {
+	m := temeToGCRF(ttCenturies(utc), eopAt(eop, utc))
+	return m.applyT(position), m.applyT(velocity)
+}
+
+// polarMotion returns the matrix rotating ITRF vectors into PEF for the pole coordinates xp and yp in radians
+func polarMotion(xp, yp float64) matrix3 {
+	sinxp, cosxp := math.Sincos(xp)
+	sinyp, cosyp := math.Sincos(yp)
+	return matrix3{
+		{cosxp, 0, -sinxp},
+		{sinxp * sinyp, cosyp, cosxp * sinyp},
+		{sinxp * cosyp, -sinyp, cosxp * cosyp},
+	}
+}
+
+// temeToPEF returns the matrix rotating TEME vectors at the instant utc into PEF along with the earth's rotation
+// vector in rad/s
+func temeToPEF(utc time.Time, eop EOP) (m matrix3, omega Vector3) {
+	ut1 := timescale.UTCToUT1(utc, eop.DUT1)
+	m = rotZ(-gstimeCenturies(julianCenturies(ut1)))
+	omega.Z = earthRotationRate * (1.0 - eop.LOD/86400.0)
+	return
+}
+
+// cross returns the cross product a x b
+func cross(a, b Vector3) Vector3 {
+	return Vector3{X: a.Y*b.Z - a.Z*b.Y, Y: a.Z*b.X - a.X*b.Z, Z: a.X*b.Y - a.Y*b.X}
+}
+
+// TEMEToPEF rotates a TEME position (km) and velocity (km/s) at the instant utc into the pseudo earth fixed frame.
+// The velocity is taken relative to the rotating earth, whose rate is corrected by the length of day eop provides.
+func TEMEToPEF(position, velocity Vector3, utc time.Time, eop EOPProvider) (Vector3, Vector3) {
+	m, omega := temeToPEF(utc, eopAt(eop, utc))
+	position = m.apply(position)
+	velocity = m.apply(velocity)
+	w := cross(omega, position)
+	return position, Vector3{X: velocity.X - w.X, Y: velocity.Y - w.Y, Z: velocity.Z - w.Z}
+}
+
+// PEFToTEME is the inverse of TEMEToPEF
+func PEFToTEME(position, velocity Vector3, utc time.Time, eop EOPProvider) (Vector3, Vector3) {
+	m, omega := temeToPEF(utc, eopAt(eop, utc))
+	w := cross(omega, position)
+	velocity = Vector3{X: velocity.X + w.X, Y: velocity.Y + w.Y, Z: velocity.Z + w.Z}
+	return m.applyT(position), m.applyT(velocity)
+}
+
+// PEFToITRF applies the polar motion XP and YP that eop provides at the instant utc to a pseudo earth fixed position
+// (km) and velocity (km/s), giving ITRF vectors. Without polar motion PEF and ITRF are the same.
+func PEFToITRF(position, velocity Vector3, utc time.Time, eop EOPProvider) (Vector3, Vector3) {
+	e := eopAt(eop, utc)
+	m := polarMotion(e.XP, e.YP)
+	return m.applyT(position), m.applyT(velocity)
+}
+
+// ITRFToPEF is the inverse of PEFToITRF
+func ITRFToPEF(position, velocity Vector3, utc time.Time, eop EOPProvider) (Vector3, Vector3) {
+	e := eopAt(eop, utc)
+	m := polarMotion(e.XP, e.YP)
+	return m.apply(position), m.apply(velocity)
+}
+
+// TEMEToITRF converts a TEME position (km) and velocity (km/s) at the instant utc into the earth fixed ITRF frame,
+// applying the UT1-UTC, length of day and polar motion eop provides
+func TEMEToITRF(position, velocity Vector3, utc time.Time, eop EOPProvider) (Vector3, Vector3) {
+	position, velocity = TEMEToPEF(position, velocity, utc, eop)
+	return PEFToITRF(position, velocity, utc, eop)
+}
+
+// ITRFToTEME is the inverse of TEMEToITRF
+func ITRFToTEME(position, velocity Vector3, utc time.Time, eop EOPProvider) (Vector3, Vector3) {
+	position, velocity = ITRFToPEF(position, velocity, utc, eop)
+	return PEFToTEME(position, velocity, utc, eop)
+}
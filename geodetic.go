@@ -0,0 +1,101 @@
+package satellite
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Ellipsoid is the reference ellipsoid geodetic latitudes and altitudes are measured from
+type Ellipsoid struct {
+	// SemiMajorAxis is the equatorial radius in km
+	SemiMajorAxis float64
+	// Flattening is (a-b)/a for the equatorial radius a and polar radius b
+	Flattening float64
+}
+
+// Reference ellipsoids of the world geodetic systems behind the preset gravity models
+var (
+	EllipsoidWGS72 = Ellipsoid{SemiMajorAxis: 6378.135, Flattening: 1.0 / 298.26}
+	EllipsoidWGS84 = Ellipsoid{SemiMajorAxis: 6378.137, Flattening: 1.0 / 298.257223563}
+)
+
+// ErrEllipsoid is returned for a gravity model without a reference ellipsoid
+var ErrEllipsoid = errors.New("gravity model has no reference ellipsoid")
+
+// GravityEllipsoid returns the reference ellipsoid of the gravity model name: WGS-72 for wgs72old and wgs72, and
+// WGS-84 for wgs84. Models added with RegisterGravity don't define a flattening and give ErrEllipsoid.
+func GravityEllipsoid(name Gravity) (Ellipsoid, error) {
+	switch name {
+	case GravityWGS72Old, GravityWGS72:
+		return EllipsoidWGS72, nil
+	case GravityWGS84:
+		return EllipsoidWGS84, nil
+	}
+	return Ellipsoid{}, fmt.Errorf("%w: %s", ErrEllipsoid, name)
+}
+
+// LLAToECEF converts a geodetic latitude and longitude in radians and an altitude in km above the ellipsoid e into
+// Earth Centered Earth Fixed coordinates in km
+func LLAToECEF(obsCoords LatLong, alt float64, e Ellipsoid) (ecfCoords Vector3) {
+	e2 := e.Flattening * (2.0 - e.Flattening)
+	sinlat, coslat := math.Sincos(obsCoords.Latitude)
+	sinlon, coslon := math.Sincos(obsCoords.Longitude)
+	// radius of curvature in the prime vertical
+	n := e.SemiMajorAxis / math.Sqrt(1.0-e2*sinlat*sinlat)
+
+	ecfCoords.X = (n + alt) * coslat * coslon
+	ecfCoords.Y = (n + alt) * coslat * sinlon
+	ecfCoords.Z = (n*(1.0-e2) + alt) * sinlat
+	return
+}
+
+// ECEFToLLA converts Earth Centered Earth Fixed coordinates in km into the geodetic latitude and longitude, in
+// radians, and altitude in km above the ellipsoid e. It uses Heikkinen's closed form solution, which is exact to
+// well under a millimetre from the earth's surface out to beyond geostationary orbit, poles included.
+// Reference: Zhu, "Conversion of Earth-centered Earth-fixed coordinates to geodetic coordinates", IEEE Transactions
+// on Aerospace and Electronic Systems, 1994.
+func ECEFToLLA(ecfCoords Vector3, e Ellipsoid) (altitude float64, ret LatLong) {
+	a := e.SemiMajorAxis
+	b := a * (1.0 - e.Flattening)
+	e2 := e.Flattening * (2.0 - e.Flattening)
+	ep2 := (a*a - b*b) / (b * b)
+	x, y, z := ecfCoords.X, ecfCoords.Y, ecfCoords.Z
+	p := math.Sqrt(x*x + y*y)
+
+	if p == 0 {
+		// on the polar axis, where the longitude is arbitrary
+		ret.Latitude = math.Copysign(math.Pi/2.0, z)
+		altitude = math.Abs(z) - b
+		return
+	}
+
+	f := 54.0 * b * b * z * z
+	g := p*p + (1.0-e2)*z*z - e2*(a*a-b*b)
+	c := e2 * e2 * f * p * p / (g * g * g)
+	s := math.Cbrt(1.0 + c + math.Sqrt(c*c+2.0*c))
+	k := s + 1.0 + 1.0/s
+	pp := f / (3.0 * k * k * g * g)
+	q := math.Sqrt(1.0 + 2.0*e2*e2*pp)
+	// near the poles the terms under the root cancel and rounding can take them below zero, where the root makes
+	// no difference to the result anyway
+	r0 := -(pp*e2*p)/(1.0+q) + math.Sqrt(math.Max(0, a*a/2.0*(1.0+1.0/q)-pp*(1.0-e2)*z*z/(q*(1.0+q))-pp*p*p/2.0))
+	u := math.Sqrt((p-e2*r0)*(p-e2*r0) + z*z)
+	v := math.Sqrt((p-e2*r0)*(p-e2*r0) + (1.0-e2)*z*z)
+	z0 := b * b * z / (a * v)
+
+	altitude = u * (1.0 - b*b/(a*v))
+	ret.Latitude = math.Atan2(z+ep2*z0, p)
+	ret.Longitude = math.Atan2(y, x)
+	return
+}
+
+// ECIToLLA converts TEME coordinates in km into the geodetic latitude, longitude and altitude above the WGS-84
+// ellipsoid, rotating them into the earth fixed frame by the greenwich sidereal time gmst in radians. velocity is the
+// speed in km/s of a circular orbit at that altitude. Use TEMEToITRF and ECEFToLLA to account for polar motion or
+// another ellipsoid.
+func ECIToLLA(eciCoords Vector3, gmst float64) (altitude, velocity float64, ret LatLong) {
+	altitude, ret = ECEFToLLA(ECIToECEF(eciCoords, gmst), EllipsoidWGS84)
+	velocity = math.Sqrt(398600.4418 / (altitude + EllipsoidWGS84.SemiMajorAxis))
+	return
+}
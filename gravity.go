@@ -1,8 +1,10 @@
 package satellite
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"sync"
 )
 
 // GravConst holds variables that are dependent upon selected gravity model
@@ -10,6 +12,7 @@ type GravConst struct {
 	mu, radiusearthkm, xke, tumin, j2, j3, j4, j3oj2 float64
 }
 
+// Gravity names a gravity model, either one of the presets or one added with RegisterGravity
 type Gravity string
 
 const (
@@ -18,6 +21,100 @@ const (
 	GravityWGS84    Gravity = "wgs84"
 )
 
+// Errors returned when defining gravity models
+var (
+	ErrGravConst       = errors.New("gravitational parameter, earth radius and J2 must be positive and the harmonics finite")
+	ErrGravityPreset   = errors.New("gravity model is a preset")
+	ErrGravityRequired = errors.New("gravity model needs a name")
+)
+
+// Gravity models added with RegisterGravity
+var (
+	customGravityMu sync.RWMutex
+	customGravity   = map[Gravity]GravConst{}
+)
+
+// NewGravConst returns a gravity model for the earth's gravitational parameter mu in km^3/s^2, its equatorial radius
+// in km and the zonal harmonics J2, J3 and J4. J2 must be positive, as SGP4 divides by it.
+func NewGravConst(mu, radiusEarthKm, j2, j3, j4 float64) (GravConst, error) {
+	if err := checkGravConst(mu, radiusEarthKm, j2, j3, j4); err != nil {
+		return GravConst{}, err
+	}
+	var grav GravConst
+	grav.mu = mu
+	grav.radiusearthkm = radiusEarthKm
+	grav.xke = 60.0 / math.Sqrt(grav.radiusearthkm*grav.radiusearthkm*grav.radiusearthkm/grav.mu)
+	grav.tumin = 1.0 / grav.xke
+	grav.j2 = j2
+	grav.j3 = j3
+	grav.j4 = j4
+	grav.j3oj2 = grav.j3 / grav.j2
+	return grav, nil
+}
+
+// checkGravConst returns ErrGravConst unless mu, the radius and j2 are positive and finite and j3 and j4 are finite
+func checkGravConst(mu, radiusEarthKm, j2, j3, j4 float64) error {
+	positive := func(v float64) bool {
+		return v > 0 && !math.IsInf(v, 1)
+	}
+	finite := func(v float64) bool {
+		return !math.IsNaN(v) && !math.IsInf(v, 0)
+	}
+	if !positive(mu) || !positive(radiusEarthKm) || !positive(j2) || !finite(j3) || !finite(j4) {
+		return fmt.Errorf("%w: mu %v, radius %v, j2 %v, j3 %v, j4 %v", ErrGravConst, mu, radiusEarthKm, j2, j3, j4)
+	}
+	return nil
+}
+
+// Mu returns the earth's gravitational parameter in km^3/s^2
+func (g GravConst) Mu() float64 {
+	return g.mu
+}
+
+// RadiusEarthKm returns the earth's equatorial radius in km
+func (g GravConst) RadiusEarthKm() float64 {
+	return g.radiusearthkm
+}
+
+// J2 returns the second zonal harmonic
+func (g GravConst) J2() float64 {
+	return g.j2
+}
+
+// J3 returns the third zonal harmonic
+func (g GravConst) J3() float64 {
+	return g.j3
+}
+
+// J4 returns the fourth zonal harmonic
+func (g GravConst) J4() float64 {
+	return g.j4
+}
+
+// RegisterGravity makes grav available under name to every function taking a Gravity. Registering a name again
+// replaces its model, but the presets can't be replaced. It is safe to call concurrently with satellites being
+// initialized.
+func RegisterGravity(name Gravity, grav GravConst) error {
+	switch name {
+	case "":
+		return ErrGravityRequired
+	case GravityWGS72Old, GravityWGS72, GravityWGS84:
+		return fmt.Errorf("%w: %s", ErrGravityPreset, name)
+	}
+	if err := checkGravConst(grav.mu, grav.radiusearthkm, grav.j2, grav.j3, grav.j4); err != nil {
+		return err
+	}
+	customGravityMu.Lock()
+	defer customGravityMu.Unlock()
+	customGravity[name] = grav
+	return nil
+}
+
+// LookupGravity returns the gravity model registered under name, which may be one of the presets
+func LookupGravity(name Gravity) (GravConst, error) {
+	return getGravConst(name)
+}
+
 // Returns a GravConst with correct information on requested model provided through the name parameter
 func getGravConst(name Gravity) (GravConst, error) {
 	var (
@@ -53,7 +150,13 @@ func getGravConst(name Gravity) (GravConst, error) {
 		grav.j4 = -0.00000161098761
 		grav.j3oj2 = grav.j3 / grav.j2
 	default:
-		err = fmt.Errorf("%s is not a valid gravity model", name)
+		customGravityMu.RLock()
+		custom, ok := customGravity[name]
+		customGravityMu.RUnlock()
+		if !ok {
+			err = fmt.Errorf("%s is not a valid gravity model", name)
+		}
+		grav = custom
 	}
 
 	return grav, err
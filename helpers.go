@@ -1,8 +1,8 @@
 package satellite
 
 import (
+	"fmt"
 	"math"
-	"strconv"
 	"strings"
 )
 
@@ -28,11 +28,15 @@ type LookAngles struct {
 }
 
 // ParseTLE parses a two line element dataset into a Satellite struct
-func ParseTLE(line1, line2 string, gravConst Gravity) (*Satellite, error) {
+// A *TLEError naming the offending line, columns and field is returned if the dataset is malformed or fails its
+// checksums. Pass SkipChecksum to accept element sets with missing or wrong checksums.
+func ParseTLE(line1, line2 string, gravConst Gravity, opts ...Option) (*Satellite, error) {
+	line1 = strings.TrimRight(line1, " \r\n")
+	line2 = strings.TrimRight(line2, " \r\n")
+
 	sat := &Satellite{
 		Line1: line1,
 		Line2: line2,
-		Error: 0,
 	}
 
 	var err error
@@ -41,75 +45,66 @@ func ParseTLE(line1, line2 string, gravConst Gravity) (*Satellite, error) {
 		return nil, err
 	}
 
+	o := newOptions(opts)
+	p := tleParser{lines: [2]string{line1, line2}}
+	p.checkLine(1, o.skipChecksum)
+	p.checkLine(2, o.skipChecksum)
+
 	// LINE 1 BEGIN
-	sat.satnum = parseInt(strings.TrimSpace(line1[2:7]))
-	sat.epochyr = parseInt(line1[18:20])
-	sat.epochdays = parseFloat(line1[20:32])
+	sat.satnum = p.parseCatalogNumber(1)
+	sat.classification = p.parseClassification()
+	sat.intldesg = strings.TrimSpace(p.text(1, 10, 17))
+	sat.epochyr = p.parseInt(1, 19, 20, "epoch year")
+	sat.epochdays = p.parseFloat(1, 21, 32, "epoch day", nil)
 
 	// These three can be negative / positive
-	sat.ndot = parseFloat(strings.Replace(line1[33:43], " ", "", 2))
-	sat.nddot = parseFloat(strings.Replace(line1[44:45]+"."+line1[45:50]+"e"+line1[50:52], " ", "", 2))
-	sat.bstar = parseFloat(strings.Replace(line1[53:54]+"."+line1[54:59]+"e"+line1[59:61], " ", "", 2))
+	sat.ndot = p.parseFloat(1, 34, 43, "first derivative of mean motion", nil)
+	sat.nddot = p.parseFloat(1, 45, 52, "second derivative of mean motion", impliedDecimal)
+	sat.bstar = p.parseFloat(1, 54, 61, "bstar drag term", impliedDecimal)
+	sat.ephtype = p.parseOptionalInt(1, 63, 63, "ephemeris type")
+	sat.elnum = p.parseOptionalInt(1, 65, 68, "element set number")
 	// LINE 1 END
 
 	// LINE 2 BEGIN
-	sat.inclo = parseFloat(strings.Replace(line2[8:16], " ", "", 2))
-	sat.nodeo = parseFloat(strings.Replace(line2[17:25], " ", "", 2))
-	sat.ecco = parseFloat("." + line2[26:33])
-	sat.argpo = parseFloat(strings.Replace(line2[34:42], " ", "", 2))
-	sat.mo = parseFloat(strings.Replace(line2[43:51], " ", "", 2))
-	sat.no = parseFloat(strings.Replace(line2[52:63], " ", "", 2))
+	if satnum := p.parseCatalogNumber(2); p.err == nil && satnum != sat.satnum {
+		p.fail(2, 3, 7, "catalog number", ErrTLECatalogMismatch)
+	}
+	sat.inclo = p.parseFloat(2, 9, 16, "inclination", nil)
+	sat.nodeo = p.parseFloat(2, 18, 25, "right ascension of ascending node", nil)
+	sat.ecco = p.parseFloat(2, 27, 33, "eccentricity", leadingDecimal)
+	sat.argpo = p.parseFloat(2, 35, 42, "argument of perigee", nil)
+	sat.mo = p.parseFloat(2, 44, 51, "mean anomaly", nil)
+	sat.no = p.parseFloat(2, 53, 63, "mean motion", nil)
+	sat.revnum = p.parseOptionalInt(2, 64, 68, "revolution number at epoch")
 	// LINE 2 END
+
+	if p.err != nil {
+		return nil, p.err
+	}
 	return sat, nil
 }
 
 // Converts a two line element data set into a Satellite struct and runs sgp4init
-func TLEToSat(line1, line2 string, gravConst Gravity) (*Satellite, error) {
-	//sat := Satellite{Line1: line1, Line2: line2}
-	sat, err := ParseTLE(line1, line2, gravConst)
+func TLEToSat(line1, line2 string, gravConst Gravity, opts ...Option) (*Satellite, error) {
+	sat, err := ParseTLE(line1, line2, gravConst, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	sat.no = sat.no / XPDOTP
-	sat.ndot = sat.ndot / (XPDOTP * 1440.0)
-	sat.nddot = sat.nddot / (XPDOTP * 1440.0 * 1440)
-
-	sat.inclo = sat.inclo * DEG2RAD
-	sat.nodeo = sat.nodeo * DEG2RAD
-	sat.argpo = sat.argpo * DEG2RAD
-	sat.mo = sat.mo * DEG2RAD
-
-	var year int64
-	if sat.epochyr < 57 {
-		year = sat.epochyr + 2000
-	} else {
-		year = sat.epochyr + 1900
+	jd, jdFrac := epochJDay(epochFullYear(sat.epochyr), sat.epochdays)
+	if err := sat.initialize(jd, jdFrac, newOptions(opts)); err != nil {
+		return nil, err
 	}
-
-	mon, day, hr, min, sec := days2mdhms(year, sat.epochdays)
-
-	sat.jdsatepoch = JDay(int(year), int(mon), int(day), int(hr), int(min), int(sec))
-
-	sgp4init(sat.jdsatepoch-2433281.5, sat)
-
 	return sat, nil
 }
 
-// Parses a string into a float64 value.
-func parseFloat(strIn string) (ret float64) {
-	ret, err := strconv.ParseFloat(strIn, 64)
-	if err != nil {
-		panic(err)
-	}
-	return ret
-}
-
-// Parses a string into a int64 value.
-func parseInt(strIn string) (ret int64) {
-	ret, err := strconv.ParseInt(strIn, 10, 0)
-	if err != nil {
-		panic(err)
+// initialize sets the satellite's epoch to the two-part Julian date jd + jdFrac and runs sgp4init on its mean elements
+func (sat *Satellite) initialize(jd, jdFrac float64, o options) error {
+	if o.opsmode != OpsModeAFSPC && o.opsmode != OpsModeImproved {
+		return fmt.Errorf("%w: %q", ErrOpsMode, o.opsmode)
 	}
-	return ret
+	sat.jdsatepoch, sat.jdsatepochF = jd, jdFrac
+	sat.rec = sgp4init(sat.whichconst, string(o.opsmode), (sat.jdsatepoch+sat.jdsatepochF)-2433281.5, sat.bstar, sat.ecco,
+		sat.argpo*DEG2RAD, sat.inclo*DEG2RAD, sat.mo*DEG2RAD, sat.no/XPDOTP, sat.nodeo*DEG2RAD)
+	return nil
 }
@@ -0,0 +1,435 @@
+package satellite
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors describing the contents of a CCSDS Orbit Mean-Elements Message. They are wrapped in an *OMMError naming the
+// keyword.
+var (
+	ErrOMMMissing    = errors.New("required keyword is missing")
+	ErrOMMTheory     = errors.New("mean element theory is not SGP4")
+	ErrOMMTimeSystem = errors.New("time system is not UTC")
+	ErrOMMSyntax     = errors.New("line is not a keyword = value pair")
+)
+
+// OMMError reports a keyword of an Orbit Mean-Elements Message that couldn't be used
+type OMMError struct {
+	// Object is the position of the message in the input, counted from 0
+	Object int
+	// Keyword is the OMM keyword, such as MEAN_MOTION. It is empty for a KVN line that couldn't be split.
+	Keyword string
+	// Value is the text of the value
+	Value string
+	// Err is the underlying error
+	Err error
+}
+
+func (e *OMMError) Error() string {
+	return fmt.Sprintf("omm %d keyword %s %q: %v", e.Object, e.Keyword, e.Value, e.Err)
+}
+
+func (e *OMMError) Unwrap() error {
+	return e.Err
+}
+
+// ommValue is the text of an OMM value. JSON values may be numbers or strings, as space-track.org quotes its numbers
+// while CelesTrak doesn't.
+type ommValue string
+
+func (v *ommValue) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*v = ommValue(s)
+		return nil
+	}
+	*v = ommValue(b)
+	return nil
+}
+
+// omm holds the keywords of an OMM that are used to initialize SGP4
+type omm struct {
+	ObjectName        ommValue `json:"OBJECT_NAME" xml:"body>segment>metadata>OBJECT_NAME"`
+	ObjectID          ommValue `json:"OBJECT_ID" xml:"body>segment>metadata>OBJECT_ID"`
+	TimeSystem        ommValue `json:"TIME_SYSTEM" xml:"body>segment>metadata>TIME_SYSTEM"`
+	MeanElementTheory ommValue `json:"MEAN_ELEMENT_THEORY" xml:"body>segment>metadata>MEAN_ELEMENT_THEORY"`
+
+	Epoch           ommValue `json:"EPOCH" xml:"body>segment>data>meanElements>EPOCH"`
+	MeanMotion      ommValue `json:"MEAN_MOTION" xml:"body>segment>data>meanElements>MEAN_MOTION"`
+	Eccentricity    ommValue `json:"ECCENTRICITY" xml:"body>segment>data>meanElements>ECCENTRICITY"`
+	Inclination     ommValue `json:"INCLINATION" xml:"body>segment>data>meanElements>INCLINATION"`
+	RAOfAscNode     ommValue `json:"RA_OF_ASC_NODE" xml:"body>segment>data>meanElements>RA_OF_ASC_NODE"`
+	ArgOfPericenter ommValue `json:"ARG_OF_PERICENTER" xml:"body>segment>data>meanElements>ARG_OF_PERICENTER"`
+	MeanAnomaly     ommValue `json:"MEAN_ANOMALY" xml:"body>segment>data>meanElements>MEAN_ANOMALY"`
+
+	EphemerisType      ommValue `json:"EPHEMERIS_TYPE" xml:"body>segment>data>tleParameters>EPHEMERIS_TYPE"`
+	ClassificationType ommValue `json:"CLASSIFICATION_TYPE" xml:"body>segment>data>tleParameters>CLASSIFICATION_TYPE"`
+	NoradCatID         ommValue `json:"NORAD_CAT_ID" xml:"body>segment>data>tleParameters>NORAD_CAT_ID"`
+	ElementSetNo       ommValue `json:"ELEMENT_SET_NO" xml:"body>segment>data>tleParameters>ELEMENT_SET_NO"`
+	RevAtEpoch         ommValue `json:"REV_AT_EPOCH" xml:"body>segment>data>tleParameters>REV_AT_EPOCH"`
+	BStar              ommValue `json:"BSTAR" xml:"body>segment>data>tleParameters>BSTAR"`
+	MeanMotionDot      ommValue `json:"MEAN_MOTION_DOT" xml:"body>segment>data>tleParameters>MEAN_MOTION_DOT"`
+	MeanMotionDDot     ommValue `json:"MEAN_MOTION_DDOT" xml:"body>segment>data>tleParameters>MEAN_MOTION_DDOT"`
+}
+
+// keywords maps the KVN keywords to the fields holding their values
+func (m *omm) keywords() map[string]*ommValue {
+	return map[string]*ommValue{
+		"OBJECT_NAME":         &m.ObjectName,
+		"OBJECT_ID":           &m.ObjectID,
+		"TIME_SYSTEM":         &m.TimeSystem,
+		"MEAN_ELEMENT_THEORY": &m.MeanElementTheory,
+		"EPOCH":               &m.Epoch,
+		"MEAN_MOTION":         &m.MeanMotion,
+		"ECCENTRICITY":        &m.Eccentricity,
+		"INCLINATION":         &m.Inclination,
+		"RA_OF_ASC_NODE":      &m.RAOfAscNode,
+		"ARG_OF_PERICENTER":   &m.ArgOfPericenter,
+		"MEAN_ANOMALY":        &m.MeanAnomaly,
+		"EPHEMERIS_TYPE":      &m.EphemerisType,
+		"CLASSIFICATION_TYPE": &m.ClassificationType,
+		"NORAD_CAT_ID":        &m.NoradCatID,
+		"ELEMENT_SET_NO":      &m.ElementSetNo,
+		"REV_AT_EPOCH":        &m.RevAtEpoch,
+		"BSTAR":               &m.BStar,
+		"MEAN_MOTION_DOT":     &m.MeanMotionDot,
+		"MEAN_MOTION_DDOT":    &m.MeanMotionDDot,
+	}
+}
+
+// ommParser converts the text of an OMM into values, keeping the first error
+type ommParser struct {
+	object int
+	err    error
+}
+
+func (p *ommParser) fail(keyword string, value ommValue, err error) {
+	if p.err == nil {
+		p.err = &OMMError{Object: p.object, Keyword: keyword, Value: string(value), Err: err}
+	}
+}
+
+func (p *ommParser) parseFloat(keyword string, value ommValue, required bool) float64 {
+	s := strings.TrimSpace(string(value))
+	if s == "" {
+		if required {
+			p.fail(keyword, value, ErrOMMMissing)
+		}
+		return 0
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		p.fail(keyword, value, err)
+	}
+	return f
+}
+
+func (p *ommParser) parseInt(keyword string, value ommValue, required bool) int64 {
+	s := strings.TrimSpace(string(value))
+	if s == "" {
+		if required {
+			p.fail(keyword, value, ErrOMMMissing)
+		}
+		return 0
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		p.fail(keyword, value, err)
+	}
+	return i
+}
+
+// parseEpoch reads an OMM epoch in either calendar or day of year form, such as 2008-09-20T12:25:40.104192 or
+// 2008-264T12:25:40.104192. The fraction of a second and a trailing Z are optional.
+func (p *ommParser) parseEpoch(value ommValue) time.Time {
+	s := strings.TrimSuffix(strings.TrimSpace(string(value)), "Z")
+	if s == "" {
+		p.fail("EPOCH", value, ErrOMMMissing)
+		return time.Time{}
+	}
+
+	if len(s) > 9 && s[4] == '-' && s[8] == 'T' {
+		year, yerr := strconv.Atoi(s[:4])
+		day, derr := strconv.Atoi(s[5:8])
+		clock, err := time.Parse("15:04:05.999999999", s[9:])
+		if yerr == nil && derr == nil && err == nil {
+			// the last day of the year is 365 or 366
+			if days := time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC).YearDay(); day < 1 || day > days {
+				p.fail("EPOCH", value, fmt.Errorf("day %d is not within 1 to %d", day, days))
+				return time.Time{}
+			}
+			midnight := time.Date(year, 1, day, 0, 0, 0, 0, time.UTC)
+			return midnight.Add(clock.Sub(time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)))
+		}
+	}
+
+	t, err := time.Parse("2006-01-02T15:04:05.999999999", s)
+	if err != nil {
+		p.fail("EPOCH", value, err)
+	}
+	return t
+}
+
+func (p *ommParser) parseClassification(value ommValue) byte {
+	switch s := strings.TrimSpace(string(value)); s {
+	case "":
+		return 'U'
+	case "U", "C", "S":
+		return s[0]
+	default:
+		p.fail("CLASSIFICATION_TYPE", value, ErrTLEClassification)
+		return 0
+	}
+}
+
+// elements checks the metadata of the message and converts its values into an element set
+func (m *omm) elements(object int) (ElementSet, error) {
+	p := ommParser{object: object}
+
+	if theory := strings.TrimSpace(string(m.MeanElementTheory)); theory != "" && !strings.EqualFold(theory, "SGP4") {
+		p.fail("MEAN_ELEMENT_THEORY", m.MeanElementTheory, ErrOMMTheory)
+	}
+	if ts := strings.TrimSpace(string(m.TimeSystem)); ts != "" && !strings.EqualFold(ts, "UTC") {
+		p.fail("TIME_SYSTEM", m.TimeSystem, ErrOMMTimeSystem)
+	}
+
+	e := ElementSet{
+		CatalogNumber:    p.parseInt("NORAD_CAT_ID", m.NoradCatID, true),
+		Classification:   p.parseClassification(m.ClassificationType),
+		IntlDesignator:   tleIntlDesignator(strings.TrimSpace(string(m.ObjectID))),
+		Epoch:            p.parseEpoch(m.Epoch),
+		MeanMotionDot:    p.parseFloat("MEAN_MOTION_DOT", m.MeanMotionDot, false),
+		MeanMotionDDot:   p.parseFloat("MEAN_MOTION_DDOT", m.MeanMotionDDot, false),
+		BStar:            p.parseFloat("BSTAR", m.BStar, true),
+		EphemerisType:    p.parseInt("EPHEMERIS_TYPE", m.EphemerisType, false),
+		ElementSetNumber: p.parseInt("ELEMENT_SET_NO", m.ElementSetNo, false),
+		Inclination:      p.parseFloat("INCLINATION", m.Inclination, true),
+		RightAscension:   p.parseFloat("RA_OF_ASC_NODE", m.RAOfAscNode, true),
+		Eccentricity:     p.parseFloat("ECCENTRICITY", m.Eccentricity, true),
+		ArgPerigee:       p.parseFloat("ARG_OF_PERICENTER", m.ArgOfPericenter, true),
+		MeanAnomaly:      p.parseFloat("MEAN_ANOMALY", m.MeanAnomaly, true),
+		MeanMotion:       p.parseFloat("MEAN_MOTION", m.MeanMotion, true),
+		RevNumber:        p.parseInt("REV_AT_EPOCH", m.RevAtEpoch, false),
+	}
+	return e, p.err
+}
+
+// satellite initializes a Satellite from the message
+func (m *omm) satellite(object int, gravConst Gravity, opts []Option) (*Satellite, error) {
+	e, err := m.elements(object)
+	if err != nil {
+		return nil, err
+	}
+	sat, err := satelliteFromElements(e, gravConst, opts...)
+	switch {
+	case errors.Is(err, ErrEccentricity):
+		return nil, &OMMError{Object: object, Keyword: "ECCENTRICITY", Value: string(m.Eccentricity), Err: ErrEccentricity}
+	case errors.Is(err, ErrMeanMotion):
+		return nil, &OMMError{Object: object, Keyword: "MEAN_MOTION", Value: string(m.MeanMotion), Err: ErrMeanMotion}
+	case err != nil:
+		return nil, err
+	}
+	sat.Name = strings.TrimSpace(string(m.ObjectName))
+	return sat, nil
+}
+
+// tleIntlDesignator converts an OMM object ID such as 1998-067A into the form used by TLEs, 98067A. IDs in any other
+// form are returned unchanged.
+func tleIntlDesignator(id string) string {
+	if len(id) < 9 || id[4] != '-' {
+		return id
+	}
+	for _, c := range id[:4] + id[5:8] {
+		if c < '0' || c > '9' {
+			return id
+		}
+	}
+	return id[2:4] + id[5:]
+}
+
+// ParseOMMJSON reads Orbit Mean-Elements Messages in the JSON form served by CelesTrak and space-track.org, either a
+// single object or an array of them, and initializes a Satellite from each just as TLEToSat would. The first message
+// that can't be used is reported as an *OMMError.
+func ParseOMMJSON(r io.Reader, gravConst Gravity, opts ...Option) ([]*Satellite, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var msgs []omm
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		msgs = make([]omm, 1)
+		err = json.Unmarshal(trimmed, &msgs[0])
+	} else {
+		err = json.Unmarshal(trimmed, &msgs)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ommSatellites(msgs, gravConst, opts)
+}
+
+// ParseOMMXML reads Orbit Mean-Elements Messages in the CCSDS XML form, either a single omm element or an ndm
+// element holding several, and initializes a Satellite from each just as TLEToSat would. The first message that can't
+// be used is reported as an *OMMError.
+func ParseOMMXML(r io.Reader, gravConst Gravity, opts ...Option) ([]*Satellite, error) {
+	var msgs []omm
+	d := xml.NewDecoder(r)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "omm" {
+			var m omm
+			if err := d.DecodeElement(&m, &start); err != nil {
+				return nil, err
+			}
+			msgs = append(msgs, m)
+		}
+	}
+	return ommSatellites(msgs, gravConst, opts)
+}
+
+// ParseOMMKVN reads Orbit Mean-Elements Messages in the CCSDS keyword = value notation and initializes a Satellite
+// from each just as TLEToSat would. Each message starts with its CCSDS_OMM_VERS line. Comments, units in brackets and
+// keywords that SGP4 doesn't use are skipped. The first message that can't be used is reported as an *OMMError.
+func ParseOMMKVN(r io.Reader, gravConst Gravity, opts ...Option) ([]*Satellite, error) {
+	var msgs []omm
+	var fields map[string]*ommValue
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "COMMENT") {
+			continue
+		}
+
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			object := len(msgs)
+			if object > 0 {
+				object--
+			}
+			return nil, &OMMError{Object: object, Value: line, Err: ErrOMMSyntax}
+		}
+		keyword := strings.TrimSpace(line[:eq])
+		value := strings.TrimSpace(line[eq+1:])
+		if i := strings.LastIndexByte(value, '['); i >= 0 && strings.HasSuffix(value, "]") {
+			value = strings.TrimSpace(value[:i])
+		}
+
+		if keyword == "CCSDS_OMM_VERS" || msgs == nil {
+			msgs = append(msgs, omm{})
+			fields = msgs[len(msgs)-1].keywords()
+		}
+		if field, ok := fields[keyword]; ok {
+			*field = ommValue(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ommSatellites(msgs, gravConst, opts)
+}
+
+func ommSatellites(msgs []omm, gravConst Gravity, opts []Option) ([]*Satellite, error) {
+	sats := make([]*Satellite, 0, len(msgs))
+	for i := range msgs {
+		sat, err := msgs[i].satellite(i, gravConst, opts)
+		if err != nil {
+			return nil, err
+		}
+		sats = append(sats, sat)
+	}
+	return sats, nil
+}
+
+// OMMCSVReader reads initialized satellites one at a time from a CSV file with a header row of OMM keywords, such as
+// the GP catalogs served by CelesTrak. Columns that SGP4 doesn't use are skipped.
+type OMMCSVReader struct {
+	csv       *csv.Reader
+	gravConst Gravity
+	opts      []Option
+
+	header []string
+	row    int
+}
+
+// NewOMMCSVReader returns an OMMCSVReader reading from r. Every row is initialized just as TLEToSat would with
+// gravConst and opts.
+func NewOMMCSVReader(r io.Reader, gravConst Gravity, opts ...Option) *OMMCSVReader {
+	c := csv.NewReader(r)
+	c.FieldsPerRecord = -1
+	c.ReuseRecord = true
+	return &OMMCSVReader{
+		csv:       c,
+		gravConst: gravConst,
+		opts:      opts,
+	}
+}
+
+// Read returns the satellite in the next row. A row that can't be read or initialized is reported as a *RecordError
+// whose Line is the row number, the header being row 1, after which Read may be called again to carry on with the
+// next row. io.EOF is returned once the input is exhausted.
+func (r *OMMCSVReader) Read() (*Satellite, error) {
+	if r.header == nil {
+		header, err := r.csv.Read()
+		if err != nil {
+			return nil, err
+		}
+		r.row++
+		r.header = make([]string, len(header))
+		for i, h := range header {
+			r.header[i] = strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		}
+	}
+
+	record, err := r.csv.Read()
+	if err == io.EOF {
+		return nil, err
+	}
+	r.row++
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, &RecordError{Line: r.row, Err: err}
+		}
+		return nil, err
+	}
+
+	var m omm
+	fields := m.keywords()
+	for i, value := range record {
+		if i >= len(r.header) {
+			break
+		}
+		if field, ok := fields[r.header[i]]; ok {
+			*field = ommValue(value)
+		}
+	}
+
+	sat, err := m.satellite(r.row-2, r.gravConst, r.opts)
+	if err != nil {
+		return nil, &RecordError{Line: r.row, Name: strings.TrimSpace(string(m.ObjectName)), Err: err}
+	}
+	return sat, nil
+}
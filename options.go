@@ -0,0 +1,50 @@
+package satellite
+
+import "errors"
+
+// Option changes how an element set is parsed or initialized
+type Option func(*options)
+
+type options struct {
+	skipChecksum bool
+	opsmode      OpsMode
+}
+
+func newOptions(opts []Option) options {
+	o := options{opsmode: OpsModeImproved}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// SkipChecksum turns off verification of TLE checksums, which is handy for hand-edited element sets. Lines without
+// a checksum column are accepted as well.
+func SkipChecksum() Option {
+	return func(o *options) {
+		o.skipChecksum = true
+	}
+}
+
+// OpsMode is the operation mode of SGP4, which picks between the behaviour of the original AFSPC code and the
+// improvements made to it since
+type OpsMode string
+
+// The operation modes of SGP4. OpsModeAFSPC computes sidereal time at epoch with the 1970 based formula of the AFSPC
+// code and keeps the node within 0 to 2pi in the Lyddane modification of dpper, as AFSPC's intrinsic functions did.
+// OpsModeImproved, the default, uses gstime and leaves the node as it is.
+const (
+	OpsModeAFSPC    OpsMode = "a"
+	OpsModeImproved OpsMode = "i"
+)
+
+// ErrOpsMode is returned for an operation mode other than OpsModeAFSPC or OpsModeImproved
+var ErrOpsMode = errors.New("unknown operation mode")
+
+// OperationMode sets the operation mode SGP4 is initialized with, which is OpsModeImproved by default. Use
+// OpsModeAFSPC to match the output of the operational AFSPC code.
+func OperationMode(mode OpsMode) Option {
+	return func(o *options) {
+		o.opsmode = mode
+	}
+}
@@ -0,0 +1,53 @@
+package satellite
+
+import "time"
+
+// Propagator is anything that can tell where an object is at a given time, such as an SGP4 Satellite. Code written
+// against it, rather than against Propagate, works unchanged with other models or with interpolated ephemerides.
+type Propagator interface {
+	// StateAt returns the position and velocity of the object at t in the TEME frame, or an error if the model can't
+	// give one for t
+	StateAt(t time.Time) (State, error)
+	// Epoch returns the reference time of the model, in UTC
+	Epoch() time.Time
+	// Metadata identifies the object and the model
+	Metadata() Metadata
+}
+
+// Metadata identifies the object a Propagator describes and the model it uses
+type Metadata struct {
+	// Name is the common name of the object, which may be empty
+	Name string
+	// CatalogNumber is the object's NORAD catalog number
+	CatalogNumber int64
+	// IntlDesignator is the international designator, such as 98067A, which may be empty
+	IntlDesignator string
+	// Model names the propagation model, such as SGP4
+	Model string
+}
+
+var _ Propagator = (*Satellite)(nil)
+
+// StateAt propagates the satellite to t with SGP4. It returns a *PropagationError if that fails.
+func (sat *Satellite) StateAt(t time.Time) (State, error) {
+	pos, vel, err := PropagateAt(sat, t)
+	if err != nil {
+		return State{}, err
+	}
+	return State{Time: t, Position: pos, Velocity: vel}, nil
+}
+
+// Epoch returns the epoch of the satellite's elements in UTC
+func (sat *Satellite) Epoch() time.Time {
+	return jdayToTime(sat.jdsatepoch, sat.jdsatepochF)
+}
+
+// Metadata returns the satellite's name, catalog number and international designator. The model is SGP4.
+func (sat *Satellite) Metadata() Metadata {
+	return Metadata{
+		Name:           sat.Name,
+		CatalogNumber:  sat.satnum,
+		IntlDesignator: sat.intldesg,
+		Model:          "SGP4",
+	}
+}
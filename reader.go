@@ -0,0 +1,142 @@
+package satellite
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrTLEIncomplete is returned for a record missing one of its lines
+var ErrTLEIncomplete = errors.New("incomplete element set")
+
+// RecordError reports a record, such as an element set, that couldn't be read from a file of records
+type RecordError struct {
+	// Line is the line number, counted from 1, at which the record starts
+	Line int
+	// Name is the name line of the record, empty for two line records
+	Name string
+	// Err is the underlying error, such as a *TLEError
+	Err error
+}
+
+func (e *RecordError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("record at line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("record at line %d (%s): %v", e.Line, e.Name, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// TLEReader reads initialized satellites one at a time from a file of element sets. Each record may be a plain two
+// line element set or be preceded by a name line, as in the three line format, and both kinds may be mixed in one
+// file. Blank lines and CRLF line endings are ignored.
+type TLEReader struct {
+	scanner   *bufio.Scanner
+	gravConst Gravity
+	opts      []Option
+
+	line    int
+	pending string
+	pushed  bool
+}
+
+// NewTLEReader returns a TLEReader reading from r. Every record is passed through TLEToSat with gravConst and opts.
+func NewTLEReader(r io.Reader, gravConst Gravity, opts ...Option) *TLEReader {
+	return &TLEReader{
+		scanner:   bufio.NewScanner(r),
+		gravConst: gravConst,
+		opts:      opts,
+	}
+}
+
+// Read returns the next satellite. A record that can't be read or initialized is reported as a *RecordError, after
+// which Read may be called again to carry on with the next record. io.EOF is returned once the input is exhausted.
+func (r *TLEReader) Read() (*Satellite, error) {
+	first, start, err := r.nextLine()
+	if err != nil {
+		return nil, err
+	}
+
+	name := ""
+	line1 := first
+	if isTLELine(first, '2') {
+		// a line 2 is never taken for a name
+		return nil, &RecordError{Line: start, Err: missingLine(1)}
+	}
+	if !isTLELine(first, '1') {
+		name = strings.TrimSpace(strings.TrimPrefix(first, "0 "))
+		line1, _, err = r.nextLine()
+		if err == io.EOF {
+			return nil, &RecordError{Line: start, Name: name, Err: missingLine(1)}
+		} else if err != nil {
+			return nil, err
+		}
+		if isTLELine(line1, '2') {
+			// line 2 belongs to this record, so it isn't pushed back to be reported a second time
+			return nil, &RecordError{Line: start, Name: name, Err: missingLine(1)}
+		}
+		if !isTLELine(line1, '1') {
+			r.pushBack(line1)
+			return nil, &RecordError{Line: start, Name: name, Err: missingLine(1)}
+		}
+	}
+
+	line2, _, err := r.nextLine()
+	if err == io.EOF {
+		return nil, &RecordError{Line: start, Name: name, Err: missingLine(2)}
+	} else if err != nil {
+		return nil, err
+	}
+	if !isTLELine(line2, '2') {
+		r.pushBack(line2)
+		return nil, &RecordError{Line: start, Name: name, Err: missingLine(2)}
+	}
+
+	sat, err := TLEToSat(line1, line2, r.gravConst, r.opts...)
+	if err != nil {
+		return nil, &RecordError{Line: start, Name: name, Err: err}
+	}
+	sat.Name = name
+	return sat, nil
+}
+
+// nextLine returns the next line that isn't blank along with its line number
+func (r *TLEReader) nextLine() (string, int, error) {
+	if r.pushed {
+		r.pushed = false
+		return r.pending, r.line, nil
+	}
+	for r.scanner.Scan() {
+		r.line++
+		l := strings.TrimRight(r.scanner.Text(), " \r")
+		if strings.TrimSpace(l) != "" {
+			r.pending = l
+			return l, r.line, nil
+		}
+	}
+	if err := r.scanner.Err(); err != nil {
+		return "", r.line, err
+	}
+	return "", r.line, io.EOF
+}
+
+// pushBack makes the line last returned by nextLine the next one returned again
+func (r *TLEReader) pushBack(line string) {
+	r.pending = line
+	r.pushed = true
+}
+
+// missingLine returns ErrTLEIncomplete for a record without the given line
+func missingLine(number int) error {
+	return fmt.Errorf("%w: missing line %d", ErrTLEIncomplete, number)
+}
+
+// isTLELine reports whether l looks like the given line of an element set
+func isTLELine(l string, number byte) bool {
+	return len(l) >= 2 && l[0] == number && l[1] == ' '
+}
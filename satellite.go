@@ -2,19 +2,25 @@ package satellite
 
 // Struct for holding satellite information during and before propagation
 type Satellite struct {
+	Name  string `json:"OBJECT_NAME"`
 	Line1 string `json:"TLE_LINE1"`
 	Line2 string `json:"TLE_LINE2"`
 
-	satnum int64
+	satnum         int64
+	classification byte
+	intldesg       string
+	ephtype        int64
+	elnum          int64
+	revnum         int64
 
-	Error      int64
-	ErrorStr   string
 	whichconst GravConst
 
-	epochyr    int64
-	epochdays  float64
-	jdsatepoch float64
+	epochyr     int64
+	epochdays   float64
+	jdsatepoch  float64
+	jdsatepochF float64
 
+	// mean elements in the units used by the TLE
 	ndot  float64
 	nddot float64
 	bstar float64
@@ -25,17 +31,28 @@ type Satellite struct {
 	mo    float64
 	no    float64
 
-	method        string
-	operationmode string
-	init          string
+	// set once by sgp4init and only read afterwards, which makes propagation safe for concurrent use
+	rec elsetrec
+}
+
+// nearEarth holds the coefficients of the near earth model, which the deep space model builds on
+type nearEarth struct {
+	whichconst GravConst
+
+	// mean elements in radians and radians per minute; no is the un-Kozai'd mean motion
+	bstar float64
+	inclo float64
+	nodeo float64
+	ecco  float64
+	argpo float64
+	mo    float64
+	no    float64
 
-	gsto    float64
 	isimp   float64
 	con41   float64
 	cc5     float64
 	d4      float64
 	argpdot float64
-	t       float64
 	t4cof   float64
 	x7thm1  float64
 	xlcof   float64
@@ -56,6 +73,16 @@ type Satellite struct {
 	x1mth2  float64
 	nodedot float64
 	nodecf  float64
+}
+
+// elsetrec holds everything sgp4 needs to propagate a satellite, as computed by sgp4init
+type elsetrec struct {
+	nearEarth
+
+	method        string
+	operationmode string
+
+	gsto float64
 
 	irez  float64
 	d3210 float64
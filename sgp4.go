@@ -2,17 +2,30 @@ package satellite
 
 import (
 	"math"
+	"time"
 )
 
 // this procedure initializes variables for sgp4.
-func sgp4init(epoch float64, satrec *Satellite) (position, velocity Vector3) {
+// epoch - julian date of the elements minus 2433281.5
+// the elements are in radians and radians per minute, with xno the Kozai mean motion from the TLE
+func sgp4init(whichconst GravConst, opsmode string, epoch, xbstar, xecco, xargpo, xinclo, xmo, xno, xnodeo float64) (rec elsetrec) {
 	var cc1sq, cc2, cc3, coef, coef1, cosio4, eeta, etasq, perige, pinvsq, psisq, qzms24, sfour, temp, temp1, temp2, temp3, temp4, tsi, xhdot1 float64
 
 	// Deep space vars
 	var cosim, sinim, em, emsq, argpm, nodem, inclm, mm, nm, s1, s2, s3, s4, s5, ss1, ss2, ss3, ss4, ss5, sz1, sz3, sz11, sz13, sz21, sz23, sz31, sz33, tc, z1, z3, z11, z13, z21, z23, z31, z33, xpidot float64
 
+	satrec := &rec
+	satrec.whichconst = whichconst
+	satrec.bstar = xbstar
+	satrec.ecco = xecco
+	satrec.argpo = xargpo
+	satrec.inclo = xinclo
+	satrec.mo = xmo
+	satrec.no = xno
+	satrec.nodeo = xnodeo
+
 	satrec.method = "n"
-	satrec.operationmode = "i"
+	satrec.operationmode = opsmode
 
 	radiusearthkm := satrec.whichconst.radiusearthkm
 	j2 := satrec.whichconst.j2
@@ -24,17 +37,12 @@ func sgp4init(epoch float64, satrec *Satellite) (position, velocity Vector3) {
 	qzms2t := qzms2ttemp * qzms2ttemp * qzms2ttemp * qzms2ttemp
 	x2o3 := 2.0 / 3.0
 
-	satrec.init = "y"
-	satrec.t = 0.0
-
 	var _, no, ao, con41, con42, cosio, cosio2, eccsq, omeosq, posq, rp, rteosq, sinio, gsto = initl(satrec.whichconst, satrec.ecco, epoch, satrec.inclo, satrec.no, satrec.method, satrec.operationmode)
 
 	satrec.no = no
 	satrec.con41 = con41
 	satrec.gsto = gsto
 
-	satrec.Error = 0
-
 	if omeosq >= 0.0 || satrec.no >= 0.0 {
 		satrec.isimp = 0
 		if rp < 220.0/radiusearthkm+1.0 {
@@ -180,7 +188,7 @@ func sgp4init(epoch float64, satrec *Satellite) (position, velocity Vector3) {
 			satrec.zmol = dscomResults.zmol
 			satrec.zmos = dscomResults.zmos
 
-			dpperResults := dpper(satrec, inclm, satrec.init, satrec.ecco, satrec.inclo, satrec.nodeo, satrec.argpo, satrec.mo, satrec.operationmode)
+			dpperResults := dpper(satrec, 0.0, inclm, "y", satrec.ecco, satrec.inclo, satrec.nodeo, satrec.argpo, satrec.mo, satrec.operationmode)
 
 			satrec.ecco = dpperResults.ep
 			satrec.inclo = dpperResults.inclp
@@ -192,7 +200,7 @@ func sgp4init(epoch float64, satrec *Satellite) (position, velocity Vector3) {
 			nodem = 0.0
 			mm = 0.0
 
-			dsinitResults := dsinit(satrec.whichconst, cosim, emsq, satrec.argpo, s1, s2, s3, s4, s5, sinim, ss1, ss2, ss3, ss4, ss5, sz1, sz3, sz11, sz13, sz21, sz23, sz31, sz33, satrec.t, tc, satrec.gsto, satrec.mo, satrec.mdot, satrec.no, satrec.nodeo, satrec.nodedot, xpidot, z1, z3, z11, z13, z21, z23, z31, z33, satrec.ecco, eccsq, em, argpm, inclm, mm, nm, nodem, satrec.atime, satrec.d2201, satrec.d2211, satrec.d3210, satrec.d3222, satrec.d4410, satrec.d4422, satrec.d5220, satrec.d5232, satrec.d5421, satrec.d5433, satrec.del1, satrec.del2, satrec.del3, satrec.xfact, satrec.xlamo, satrec.xli, satrec.xni)
+			dsinitResults := dsinit(satrec.whichconst, cosim, emsq, satrec.argpo, s1, s2, s3, s4, s5, sinim, ss1, ss2, ss3, ss4, ss5, sz1, sz3, sz11, sz13, sz21, sz23, sz31, sz33, 0.0, tc, satrec.gsto, satrec.mo, satrec.mdot, satrec.no, satrec.nodeo, satrec.nodedot, xpidot, z1, z3, z11, z13, z21, z23, z31, z33, satrec.ecco, eccsq, em, argpm, inclm, mm, nm, nodem, satrec.atime, satrec.d2201, satrec.d2211, satrec.d3210, satrec.d3222, satrec.d4410, satrec.d4422, satrec.d5220, satrec.d5232, satrec.d5421, satrec.d5433, satrec.del1, satrec.del2, satrec.del3, satrec.xfact, satrec.xlamo, satrec.xli, satrec.xni)
 
 			satrec.irez = dsinitResults.irez
 			satrec.atime = dsinitResults.atime
@@ -232,9 +240,6 @@ func sgp4init(epoch float64, satrec *Satellite) (position, velocity Vector3) {
 		}
 	}
 
-	position, velocity = sgp4(satrec, 0.0)
-	satrec.init = "n"
-
 	return
 }
 
@@ -286,154 +291,235 @@ func initl(grav GravConst, ecco, epoch, inclo, noIn float64, methodIn, opsmode s
 }
 
 // Calculates position and velocity vectors for given time
+// Zero vectors are returned if propagation fails; use PropagateAt to find out why.
 func Propagate(sat Satellite, year int, month int, day, hours, minutes, seconds int) (position, velocity Vector3) {
-	j := JDay(year, month, day, hours, minutes, seconds)
-	m := (j - sat.jdsatepoch) * 1440
-	return sgp4(&sat, m)
+	position, velocity, _ = PropagateAt(&sat, time.Date(year, time.Month(month), day, hours, minutes, seconds, 0, time.UTC))
+	return
+}
+
+// PropagateAt calculates position and velocity vectors for the instant t. t is converted to UTC and keeps its full
+// nanosecond resolution. A *PropagationError is returned if the satellite can't be propagated to t.
+func PropagateAt(sat *Satellite, t time.Time) (position, velocity Vector3, err error) {
+	return PropagateMinutes(sat, sat.minutesSinceEpoch(t))
+}
+
+// minutesSinceEpoch returns the time from the satellite's epoch to t in minutes
+func (sat *Satellite) minutesSinceEpoch(t time.Time) float64 {
+	jd, jdFrac := jdayTime(t)
+	return ((jd - sat.jdsatepoch) + (jdFrac - sat.jdsatepochF)) * 1440.0
+}
+
+// PropagateMinutes calculates position and velocity vectors for the given number of minutes since the satellite's
+// epoch. Negative values propagate backwards in time. A *PropagationError is returned if the satellite can't be
+// propagated to tsince.
+//
+// Like every propagation function in this package it returns positions in km and velocities in km/s in the TEME
+// frame; TEMEToJ2000 and TEMEToGCRF rotate them into an inertial frame.
+func PropagateMinutes(sat *Satellite, tsince float64) (position, velocity Vector3, err error) {
+	return sgp4(&sat.rec, tsince)
 }
 
 // this procedure is the sgp4 prediction model from space command. this is an updated and combined version of sgp4 and sdp4, which were originally published separately in spacetrack report #3. this version follows the methodology from the aiaa paper (2006) describing the history and development of the code.
-// satrec - initialized Satellite struct from sgp4init
+// satrec - initialized record from sgp4init, which is only read so it may be shared between goroutines
 // tsince - time since epoch in minutes
-func sgp4(satrec *Satellite, tsince float64) (position, velocity Vector3) {
-	var am, axnl, aynl, betal, cosim, sinim, cnod, snod, cos2u, sin2u, coseo1, sineo1, cosi, sini, cosip, sinip, cosisq, cossu, sinsu, cosu, sinu, delm, delomg, ecose, el2, eo1, esine, argpm, argpp, pl, rdotl, rl, rvdot, rvdotl, su, t2, t3, t4, tc, tem5, temp, temp1, temp2, tempa, tempe, templ, u, ux, uy, uz, vx, vy, vz, inclm, mm, nm, nodem, xinc, xincp, xl, xlm, mp, xmdf, xmx, xmy, nodedf, xnode, nodep, mrt float64
+// position and velocity are zero when a *PropagationError is returned.
+func sgp4(satrec *elsetrec, tsince float64) (position, velocity Vector3, err error) {
+	var code int
+	var value float64
+	if satrec.method == "d" {
+		position, velocity, code, value = satrec.propagateDeep(tsince)
+	} else {
+		position, velocity, code, value = satrec.nearEarth.propagate(tsince)
+	}
+	if code != 0 {
+		return Vector3{}, Vector3{}, newPropagationError(code, tsince, value)
+	}
+	return
+}
 
-	mrt = 0.0
-	temp4 := 1.5e-12
-	x2o3 := 2.0 / 3.0
+// propagate is sgp4 for a near earth satellite t minutes from its epoch. It returns the reference error code and the
+// offending value instead of an error, so that Batch can call it without allocating.
+func (c *nearEarth) propagate(t float64) (position, velocity Vector3, code int, value float64) {
+	s := c.secular(t)
+	if code, value = c.drag(&s); code != 0 {
+		return
+	}
+	return c.periodics(&s, c.shortPeriod(s.inclm))
+}
 
-	radiusearthkm := satrec.whichconst.radiusearthkm
-	xke := satrec.whichconst.xke
-	j2 := satrec.whichconst.j2
-	j3oj2 := satrec.whichconst.j3oj2
+// propagateDeep is sgp4 for a deep space satellite, which adds the resonance and lunar-solar terms of dspace and dpper
+// to the near earth model
+func (satrec *elsetrec) propagateDeep(t float64) (position, velocity Vector3, code int, value float64) {
+	c := &satrec.nearEarth
+	s := c.secular(t)
 
-	vkmpersec := radiusearthkm * xke / 60.0
+	tc := t
+	dspaceResult := dspace(satrec.irez, satrec.d2201, satrec.d2211, satrec.d3210, satrec.d3222, satrec.d4410, satrec.d4422, satrec.d5220, satrec.d5232, satrec.d5421, satrec.d5433, satrec.dedt, satrec.del1, satrec.del2, satrec.del3, satrec.didt, satrec.dmdt, satrec.dnodt, satrec.domdt, satrec.argpo, satrec.argpdot, t, tc, satrec.gsto, satrec.xfact, satrec.xlamo, satrec.no, satrec.atime, s.em, s.argpm, s.inclm, satrec.xli, s.mm, satrec.xni, s.nodem, s.nm)
 
-	satrec.t = tsince
-	satrec.Error = 0
-	// TODO: satrec.Error_message    = nil
-
-	xmdf = satrec.mo + satrec.mdot*satrec.t
-	var argpdf = satrec.argpo + satrec.argpdot*satrec.t
-	nodedf = satrec.nodeo + satrec.nodedot*satrec.t
-	argpm = argpdf
-	mm = xmdf
-	t2 = satrec.t * satrec.t
-	nodem = nodedf + satrec.nodecf*t2
-	tempa = 1.0 - satrec.cc1*satrec.t
-	tempe = satrec.bstar * satrec.cc4 * satrec.t
-	templ = satrec.t2cof * t2
-
-	if satrec.isimp != 1 {
-		delomg = satrec.omgcof * satrec.t
-		delmtemp := 1.0 + satrec.eta*math.Cos(xmdf)
-		delm = satrec.xmcof * (delmtemp*delmtemp*delmtemp - satrec.delmo)
-		temp = delomg + delm
-		mm = xmdf + temp
-		argpm = argpdf - temp
-		t3 = t2 * satrec.t
-		t4 = t3 * satrec.t
-		tempa = tempa - satrec.d2*t2 - satrec.d3*t3 - satrec.d4*t4
-		tempe = tempe + satrec.bstar*satrec.cc5*(math.Sin(mm)-satrec.sinmao)
-		templ = templ + satrec.t3cof*t3 + t4*(satrec.t4cof+satrec.t*satrec.t5cof)
+	s.em = dspaceResult.em
+	s.argpm = dspaceResult.argpm
+	s.inclm = dspaceResult.inclm
+	s.mm = dspaceResult.mm
+	s.nodem = dspaceResult.nodem
+	s.nm = dspaceResult.nm
+
+	if code, value = c.drag(&s); code != 0 {
+		return
 	}
 
-	nm = satrec.no
-	em := satrec.ecco
-	inclm = satrec.inclo
+	dpperResults := dpper(satrec, t, satrec.inclo, "n", s.em, s.inclm, s.nodem, s.argpm, s.mm, satrec.operationmode)
 
-	if satrec.method == "d" {
-		tc = satrec.t
+	s.em = dpperResults.ep
+	s.inclm = dpperResults.inclp
+	s.nodem = dpperResults.nodep
+	s.argpm = dpperResults.argpp
+	s.mm = dpperResults.mp
+
+	if s.inclm < 0.0 {
+		s.inclm = -s.inclm
+		s.nodem = s.nodem + math.Pi
+		s.argpm = s.argpm - math.Pi
+	}
+
+	if s.em < 0.0 || s.em > 1.0 {
+		return Vector3{}, Vector3{}, 3, s.em
+	}
+
+	return c.periodics(&s, deepShortPeriod(s.inclm, c.whichconst.j3oj2))
+}
 
-		dspaceResult := dspace(satrec.irez, satrec.d2201, satrec.d2211, satrec.d3210, satrec.d3222, satrec.d4410, satrec.d4422, satrec.d5220, satrec.d5232, satrec.d5421, satrec.d5433, satrec.dedt, satrec.del1, satrec.del2, satrec.del3, satrec.didt, satrec.dmdt, satrec.dnodt, satrec.domdt, satrec.argpo, satrec.argpdot, satrec.t, tc, satrec.gsto, satrec.xfact, satrec.xlamo, satrec.no, satrec.atime, em, argpm, inclm, satrec.xli, mm, satrec.xni, nodem, nm)
+// meanElements are the mean elements of a satellite as sgp4 carries them from its epoch to the time of propagation
+type meanElements struct {
+	am, nm, em, inclm, argpm, nodem, mm float64
+	// drag terms of the semi-major axis, eccentricity and mean longitude
+	tempa, tempe, templ float64
+}
 
-		em = dspaceResult.em
-		argpm = dspaceResult.argpm
-		inclm = dspaceResult.inclm
-		mm = dspaceResult.mm
-		nodem = dspaceResult.nodem
-		nm = dspaceResult.nm
+// secular applies the secular effects of gravity and atmospheric drag t minutes from epoch
+func (c *nearEarth) secular(t float64) (s meanElements) {
+	xmdf := c.mo + c.mdot*t
+	argpdf := c.argpo + c.argpdot*t
+	nodedf := c.nodeo + c.nodedot*t
+	s.argpm = argpdf
+	s.mm = xmdf
+	t2 := t * t
+	s.nodem = nodedf + c.nodecf*t2
+	s.tempa = 1.0 - c.cc1*t
+	s.tempe = c.bstar * c.cc4 * t
+	s.templ = c.t2cof * t2
+
+	if c.isimp != 1 {
+		delomg := c.omgcof * t
+		delmtemp := 1.0 + c.eta*math.Cos(xmdf)
+		delm := c.xmcof * (delmtemp*delmtemp*delmtemp - c.delmo)
+		temp := delomg + delm
+		s.mm = xmdf + temp
+		s.argpm = argpdf - temp
+		t3 := t2 * t
+		t4 := t3 * t
+		s.tempa = s.tempa - c.d2*t2 - c.d3*t3 - c.d4*t4
+		s.tempe = s.tempe + c.bstar*c.cc5*(math.Sin(s.mm)-c.sinmao)
+		s.templ = s.templ + c.t3cof*t3 + t4*(c.t4cof+t*c.t5cof)
 	}
 
-	if nm < 0.0 {
-		satrec.Error = 2
-		satrec.ErrorStr = "Mean motion is less than zero"
+	s.nm = c.no
+	s.em = c.ecco
+	s.inclm = c.inclo
+	return
+}
+
+// drag applies the drag terms of s to its semi-major axis, mean motion, eccentricity and mean anomaly, and reduces its
+// angles to a revolution. It returns the error code and offending value of a mean motion or eccentricity out of
+// range.
+func (c *nearEarth) drag(s *meanElements) (code int, value float64) {
+	const x2o3 = 2.0 / 3.0
+	xke := c.whichconst.xke
+
+	if s.nm <= 0.0 {
+		return 2, s.nm
 	}
 
-	am = math.Pow((xke/nm), x2o3) * tempa * tempa
-	nm = xke / math.Pow(am, 1.5)
-	em = em - tempe
+	s.am = math.Pow((xke/s.nm), x2o3) * s.tempa * s.tempa
+	s.nm = xke / math.Pow(s.am, 1.5)
+	s.em = s.em - s.tempe
 
-	if em >= 1.0 || em < -0.001 {
-		satrec.Error = 1
-		satrec.ErrorStr = "mean eccentricity not within range 0.0 <= e < 1.0"
+	if s.em >= 1.0 || s.em < -0.001 {
+		return 1, s.em
 	}
 
-	if em < 1.0e-6 {
-		em = 1.0e-6
+	if s.em < 1.0e-6 {
+		s.em = 1.0e-6
 	}
-	mm = mm + satrec.no*templ
-	xlm = mm + argpm + nodem
+	s.mm = s.mm + c.no*s.templ
+	xlm := s.mm + s.argpm + s.nodem
 
-	nodem = math.Mod(nodem, TWOPI)
-	argpm = math.Mod(argpm, TWOPI)
+	s.nodem = math.Mod(s.nodem, TWOPI)
+	s.argpm = math.Mod(s.argpm, TWOPI)
 	xlm = math.Mod(xlm, TWOPI)
-	mm = math.Mod((xlm - argpm - nodem), TWOPI)
+	s.mm = math.Mod((xlm - s.argpm - s.nodem), TWOPI)
+	return 0, 0
+}
 
-	sinim = math.Sin(inclm)
-	cosim = math.Cos(inclm)
+// shortPeriod holds the terms of the periodics that depend on the inclination
+type shortPeriod struct {
+	sinip, cosip, con41, x1mth2, x7thm1, aycof, xlcof float64
+}
 
-	ep := em
-	xincp = inclm
-	argpp = argpm
-	nodep = nodem
-	mp = mm
-	sinip = sinim
-	cosip = cosim
+// shortPeriod returns the terms sgp4init computed for the near earth model, whose inclination is inclp
+func (c *nearEarth) shortPeriod(inclp float64) shortPeriod {
+	return shortPeriod{
+		sinip:  math.Sin(inclp),
+		cosip:  math.Cos(inclp),
+		con41:  c.con41,
+		x1mth2: c.x1mth2,
+		x7thm1: c.x7thm1,
+		aycof:  c.aycof,
+		xlcof:  c.xlcof,
+	}
+}
 
-	if satrec.method == "d" {
-		dpperResults := dpper(satrec, satrec.inclo, "n", ep, xincp, nodep, argpp, mp, satrec.operationmode)
-
-		ep = dpperResults.ep
-		xincp = dpperResults.inclp
-		nodep = dpperResults.nodep
-		argpp = dpperResults.argpp
-		mp = dpperResults.mp
-
-		if xincp < 0.0 {
-			xincp = -xincp
-			nodep = nodep + math.Pi
-			argpp = argpp - math.Pi
-		}
+// deepShortPeriod recalculates the terms for the inclination xincp perturbed by the deep space periodics
+func deepShortPeriod(xincp, j3oj2 float64) (p shortPeriod) {
+	const temp4 = 1.5e-12
 
-		if ep < 0.0 || ep > 1.0 {
-			satrec.Error = 3
-			satrec.ErrorStr = "perturbed eccentricity not within range 0.0 <= e <= 1.0"
-		}
+	p.sinip = math.Sin(xincp)
+	p.cosip = math.Cos(xincp)
+	p.aycof = -0.5 * j3oj2 * p.sinip
+	if math.Abs(p.cosip+1.0) > 1.5e-12 {
+		p.xlcof = -0.25 * j3oj2 * p.sinip * (3.0 + 5.0*p.cosip) / (1.0 + p.cosip)
+	} else {
+		p.xlcof = -0.25 * j3oj2 * p.sinip * (3.0 + 5.0*p.cosip) / temp4
 	}
 
-	if satrec.method == "d" {
-		sinip = math.Sin(xincp)
-		cosip = math.Cos(xincp)
-		satrec.aycof = -0.5 * j3oj2 * sinip
-		if math.Abs(cosip+1.0) > 1.5e-12 {
-			satrec.xlcof = -0.25 * j3oj2 * sinip * (3.0 + 5.0*cosip) / (1.0 + cosip)
-		} else {
-			satrec.xlcof = -0.25 * j3oj2 * sinip * (3.0 + 5.0*cosip) / temp4
-		}
-	}
+	cosisq := p.cosip * p.cosip
+	p.con41 = 3.0*cosisq - 1.0
+	p.x1mth2 = 1.0 - cosisq
+	p.x7thm1 = 7.0*cosisq - 1.0
+	return
+}
 
-	axnl = ep * math.Cos(argpp)
-	temp = 1.0 / (am * (1.0 - ep*ep))
-	aynl = ep*math.Sin(argpp) + temp*satrec.aycof
-	xl = mp + argpp + nodep + temp*satrec.xlcof*axnl
+// periodics solves kepler's equation for the elements s and applies the short period periodics with the terms p. It
+// returns the position in km and velocity in km/s, or the error code and offending value of a semilatus rectum below
+// zero or a satellite below the earth's surface.
+func (c *nearEarth) periodics(s *meanElements, p shortPeriod) (position, velocity Vector3, code int, value float64) {
+	radiusearthkm := c.whichconst.radiusearthkm
+	xke := c.whichconst.xke
+	j2 := c.whichconst.j2
+	vkmpersec := radiusearthkm * xke / 60.0
+
+	am, nm := s.am, s.nm
+	ep, xincp, argpp, nodep, mp := s.em, s.inclm, s.argpm, s.nodem, s.mm
 
-	u = math.Mod(xl-nodep, TWOPI)
-	eo1 = u
-	tem5 = 9999.9
-	ktr := 1
+	axnl := ep * math.Cos(argpp)
+	temp := 1.0 / (am * (1.0 - ep*ep))
+	aynl := ep*math.Sin(argpp) + temp*p.aycof
+	xl := mp + argpp + nodep + temp*p.xlcof*axnl
 
-	for math.Abs(tem5) >= 1.0e-12 && ktr <= 10 {
+	u := math.Mod(xl-nodep, TWOPI)
+	eo1 := u
+	tem5 := 9999.9
+	var sineo1, coseo1 float64
+	for ktr := 1; math.Abs(tem5) >= 1.0e-12 && ktr <= 10; ktr++ {
 		sineo1 = math.Sin(eo1)
 		coseo1 = math.Cos(eo1)
 		tem5 = 1.0 - coseo1*axnl - sineo1*aynl
@@ -446,76 +532,66 @@ func sgp4(satrec *Satellite, tsince float64) (position, velocity Vector3) {
 			}
 		}
 		eo1 = eo1 + tem5
-		ktr = ktr + 1
 	}
 
-	ecose = axnl*coseo1 + aynl*sineo1
-	esine = axnl*sineo1 - aynl*coseo1
-	el2 = axnl*axnl + aynl*aynl
-	pl = am * (1.0 - el2)
+	ecose := axnl*coseo1 + aynl*sineo1
+	esine := axnl*sineo1 - aynl*coseo1
+	el2 := axnl*axnl + aynl*aynl
+	pl := am * (1.0 - el2)
 
 	if pl < 0.0 {
-		satrec.Error = 4
-		satrec.ErrorStr = "semilatus rectum is less than zero"
-	} else {
-		rl = am * (1.0 - ecose)
-		rdotl = math.Sqrt(am) * esine / rl
-		rvdotl = math.Sqrt(pl) / rl
-		betal = math.Sqrt(1.0 - el2)
-		temp = esine / (1.0 + betal)
-		sinu = am / rl * (sineo1 - aynl - axnl*temp)
-		cosu = am / rl * (coseo1 - axnl + aynl*temp)
-		su = math.Atan2(sinu, cosu)
-		sin2u = (cosu + cosu) * sinu
-		cos2u = 1.0 - 2.0*sinu*sinu
-		temp = 1.0 / pl
-		temp1 = 0.5 * j2 * temp
-		temp2 = temp1 * temp
-
-		if satrec.method == "d" {
-			cosisq = cosip * cosip
-			satrec.con41 = 3.0*cosisq - 1.0
-			satrec.x1mth2 = 1.0 - cosisq
-			satrec.x7thm1 = 7.0*cosisq - 1.0
-		}
-
-		mrt = rl*(1.0-1.5*temp2*betal*satrec.con41) + 0.5*temp1*satrec.x1mth2*cos2u
-		su = su - 0.25*temp2*satrec.x7thm1*sin2u
-		xnode = nodep + 1.5*temp2*cosip*sin2u
-		xinc = xincp + 1.5*temp2*cosip*sinip*cos2u
-		mvt := rdotl - nm*temp1*satrec.x1mth2*sin2u/xke
-		rvdot = rvdotl + nm*temp1*(satrec.x1mth2*cos2u+1.5*satrec.con41)/xke
-
-		sinsu = math.Sin(su)
-		cossu = math.Cos(su)
-		snod = math.Sin(xnode)
-		cnod = math.Cos(xnode)
-		sini = math.Sin(xinc)
-		cosi = math.Cos(xinc)
-		xmx = -snod * cosi
-		xmy = cnod * cosi
-		ux = xmx*sinsu + cnod*cossu
-		uy = xmy*sinsu + snod*cossu
-		uz = sini * sinsu
-		vx = xmx*cossu - cnod*sinsu
-		vy = xmy*cossu - snod*sinsu
-		vz = sini * cossu
-
-		_mr := mrt * radiusearthkm
-
-		position.X = _mr * ux
-		position.Y = _mr * uy
-		position.Z = _mr * uz
-
-		velocity.X = (mvt*ux + rvdot*vx) * vkmpersec
-		velocity.Y = (mvt*uy + rvdot*vy) * vkmpersec
-		velocity.Z = (mvt*uz + rvdot*vz) * vkmpersec
+		return Vector3{}, Vector3{}, 4, pl
 	}
 
+	rl := am * (1.0 - ecose)
+	rdotl := math.Sqrt(am) * esine / rl
+	rvdotl := math.Sqrt(pl) / rl
+	betal := math.Sqrt(1.0 - el2)
+	temp = esine / (1.0 + betal)
+	sinu := am / rl * (sineo1 - aynl - axnl*temp)
+	cosu := am / rl * (coseo1 - axnl + aynl*temp)
+	su := math.Atan2(sinu, cosu)
+	sin2u := (cosu + cosu) * sinu
+	cos2u := 1.0 - 2.0*sinu*sinu
+	temp = 1.0 / pl
+	temp1 := 0.5 * j2 * temp
+	temp2 := temp1 * temp
+
+	mrt := rl*(1.0-1.5*temp2*betal*p.con41) + 0.5*temp1*p.x1mth2*cos2u
+	su = su - 0.25*temp2*p.x7thm1*sin2u
+	xnode := nodep + 1.5*temp2*p.cosip*sin2u
+	xinc := xincp + 1.5*temp2*p.cosip*p.sinip*cos2u
+	mvt := rdotl - nm*temp1*p.x1mth2*sin2u/xke
+	rvdot := rvdotl + nm*temp1*(p.x1mth2*cos2u+1.5*p.con41)/xke
+
 	if mrt < 1.0 {
-		satrec.Error = 6
-		satrec.ErrorStr = "mrt is less than 1.0 indicating the satellite has decayed"
+		return Vector3{}, Vector3{}, 6, mrt
 	}
 
-	return
+	sinsu := math.Sin(su)
+	cossu := math.Cos(su)
+	snod := math.Sin(xnode)
+	cnod := math.Cos(xnode)
+	sini := math.Sin(xinc)
+	cosi := math.Cos(xinc)
+	xmx := -snod * cosi
+	xmy := cnod * cosi
+	ux := xmx*sinsu + cnod*cossu
+	uy := xmy*sinsu + snod*cossu
+	uz := sini * sinsu
+	vx := xmx*cossu - cnod*sinsu
+	vy := xmy*cossu - snod*sinsu
+	vz := sini * cossu
+
+	mr := mrt * radiusearthkm
+
+	position.X = mr * ux
+	position.Y = mr * uy
+	position.Z = mr * uz
+
+	velocity.X = (mvt*ux + rvdot*vx) * vkmpersec
+	velocity.Y = (mvt*uy + rvdot*vy) * vkmpersec
+	velocity.Z = (mvt*uz + rvdot*vz) * vkmpersec
+
+	return position, velocity, 0, 0
 }
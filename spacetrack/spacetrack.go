@@ -45,6 +45,7 @@ const noradCatID field = "NORAD_CAT_ID"
 
 var ErrInvalidResponseCode = errors.New("Invalid response from spacetrack")
 var ErrNotSingleSat = errors.New("not a single satellite returned")
+var ErrInvalidCatalogID = errors.New("catalog ID does not fit in a TLE")
 
 // Spacetrack contains an initialised API interface to space-track.org
 type Spacetrack struct {
@@ -63,8 +64,13 @@ func NewSpacetrack(username, password string) *Spacetrack {
 }
 
 // GetTLE generates a Satellite from the most recent TLE from space-track.org before the given time
+// Catalog IDs above 99999 are written in the Alpha-5 scheme by space-track.org, which reaches up to
+// satellite.MaxCatalogNumber.
 func (s *Spacetrack) GetTLE(catid uint64, ts time.Time, gravConst satellite.Gravity) (satellite.Satellite, error) {
 	zero := satellite.Satellite{}
+	if catid > satellite.MaxCatalogNumber {
+		return zero, errors.Wrap(ErrInvalidCatalogID, fmt.Sprint(catid))
+	}
 	args := spacetrackArgs{
 		base:         baseurl,
 		class:        tle,
@@ -112,6 +118,10 @@ func (s *Spacetrack) GetTLE(catid uint64, ts time.Time, gravConst satellite.Grav
 
 	}
 	sat, err := satellite.TLEToSat(sats[0].Line1, sats[0].Line2, gravConst)
+	if err != nil {
+		return zero, err
+	}
+	sat.Name = sats[0].Name
 	return *sat, nil
 }
 
@@ -3,7 +3,9 @@ package spacetrack
 import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 	"testing"
+	"time"
 )
 
 func TestSpacetrack(t *testing.T) {
@@ -121,4 +123,11 @@ var _ = Describe("spacetrack tests", func() {
 			})
 		})
 	}
+
+	Describe("GetTLE", func() {
+		It("should reject catalog IDs beyond Alpha-5", func() {
+			_, err := NewSpacetrack("", "").GetTLE(340000, time.Now(), "wgs84")
+			Expect(errors.Cause(err)).To(Equal(ErrInvalidCatalogID))
+		})
+	})
 })
@@ -0,0 +1,131 @@
+package timescale
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors returned by LoadLeapSeconds
+var (
+	ErrLeapSecondsEmpty = errors.New("no leap seconds found")
+	ErrLeapSecondsOrder = errors.New("leap seconds are not in increasing order")
+)
+
+// leapSecond is TAI-UTC from the start of a UTC day onwards
+type leapSecond struct {
+	start time.Time
+	dat   int
+}
+
+// builtinLeapSeconds is the IERS table of TAI-UTC since leap seconds were introduced in 1972, up to the one at the
+// end of 2016
+var builtinLeapSeconds = []leapSecond{
+	{time.Date(1972, 1, 1, 0, 0, 0, 0, time.UTC), 10},
+	{time.Date(1972, 7, 1, 0, 0, 0, 0, time.UTC), 11},
+	{time.Date(1973, 1, 1, 0, 0, 0, 0, time.UTC), 12},
+	{time.Date(1974, 1, 1, 0, 0, 0, 0, time.UTC), 13},
+	{time.Date(1975, 1, 1, 0, 0, 0, 0, time.UTC), 14},
+	{time.Date(1976, 1, 1, 0, 0, 0, 0, time.UTC), 15},
+	{time.Date(1977, 1, 1, 0, 0, 0, 0, time.UTC), 16},
+	{time.Date(1978, 1, 1, 0, 0, 0, 0, time.UTC), 17},
+	{time.Date(1979, 1, 1, 0, 0, 0, 0, time.UTC), 18},
+	{time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), 19},
+	{time.Date(1981, 7, 1, 0, 0, 0, 0, time.UTC), 20},
+	{time.Date(1982, 7, 1, 0, 0, 0, 0, time.UTC), 21},
+	{time.Date(1983, 7, 1, 0, 0, 0, 0, time.UTC), 22},
+	{time.Date(1985, 7, 1, 0, 0, 0, 0, time.UTC), 23},
+	{time.Date(1988, 1, 1, 0, 0, 0, 0, time.UTC), 24},
+	{time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), 25},
+	{time.Date(1991, 1, 1, 0, 0, 0, 0, time.UTC), 26},
+	{time.Date(1992, 7, 1, 0, 0, 0, 0, time.UTC), 27},
+	{time.Date(1993, 7, 1, 0, 0, 0, 0, time.UTC), 28},
+	{time.Date(1994, 7, 1, 0, 0, 0, 0, time.UTC), 29},
+	{time.Date(1996, 1, 1, 0, 0, 0, 0, time.UTC), 30},
+	{time.Date(1997, 7, 1, 0, 0, 0, 0, time.UTC), 31},
+	{time.Date(1999, 1, 1, 0, 0, 0, 0, time.UTC), 32},
+	{time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC), 33},
+	{time.Date(2009, 1, 1, 0, 0, 0, 0, time.UTC), 34},
+	{time.Date(2012, 7, 1, 0, 0, 0, 0, time.UTC), 35},
+	{time.Date(2015, 7, 1, 0, 0, 0, 0, time.UTC), 36},
+	{time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), 37},
+}
+
+var (
+	leapSecondsMu sync.RWMutex
+	leapSeconds   = builtinLeapSeconds
+)
+
+// LeapSeconds returns TAI-UTC in whole seconds at the instant t, read as UTC. Instants before 1972 get the 10 seconds
+// TAI-UTC started at, as the fractional offsets used before then aren't modelled.
+func LeapSeconds(t time.Time) int {
+	leapSecondsMu.RLock()
+	table := leapSeconds
+	leapSecondsMu.RUnlock()
+
+	i := sort.Search(len(table), func(i int) bool {
+		return table[i].start.After(t)
+	})
+	if i == 0 {
+		return table[0].dat
+	}
+	return table[i-1].dat
+}
+
+// LoadLeapSeconds replaces the leap second table with the one read from r in the format of the IERS Leap_Second.dat
+// file: lines of MJD, day, month, year and TAI-UTC, with comments starting with '#'. A line that can't be parsed is
+// reported with its line number and the table is left unchanged.
+func LoadLeapSeconds(r io.Reader) error {
+	var table []leapSecond
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) < 5 {
+			return fmt.Errorf("line %d: expected MJD, day, month, year and TAI-UTC", line)
+		}
+		var v [4]int
+		for i, field := range fields[1:5] {
+			n, err := strconv.Atoi(field)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			v[i] = n
+		}
+		start := time.Date(v[2], time.Month(v[1]), v[0], 0, 0, 0, 0, time.UTC)
+		if n := len(table); n > 0 && !start.After(table[n-1].start) {
+			return fmt.Errorf("line %d: %w", line, ErrLeapSecondsOrder)
+		}
+		table = append(table, leapSecond{start: start, dat: v[3]})
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(table) == 0 {
+		return ErrLeapSecondsEmpty
+	}
+
+	leapSecondsMu.Lock()
+	leapSeconds = table
+	leapSecondsMu.Unlock()
+	return nil
+}
+
+// ResetLeapSeconds restores the built-in leap second table
+func ResetLeapSeconds() {
+	leapSecondsMu.Lock()
+	leapSeconds = builtinLeapSeconds
+	leapSecondsMu.Unlock()
+}
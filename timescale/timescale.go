@@ -0,0 +1,137 @@
+// Package timescale converts instants between the time scales used in astrodynamics: UTC, TAI, TT, TDB, GPS and UT1.
+//
+// An instant in a time scale is represented by a time.Time whose clock, read in UTC, shows that scale, and results
+// are returned in time.UTC. UTC instants inside a leap second can't be represented by a time.Time and are mapped onto
+// the second that follows it.
+package timescale
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Scale identifies a time scale
+type Scale int
+
+// Time scales
+const (
+	UTC Scale = iota // Coordinated Universal Time
+	TAI              // International Atomic Time
+	TT               // Terrestrial Time
+	TDB              // Barycentric Dynamical Time
+	GPS              // GPS system time
+	UT1              // Universal Time, following the earth's rotation
+)
+
+func (s Scale) String() string {
+	switch s {
+	case UTC:
+		return "UTC"
+	case TAI:
+		return "TAI"
+	case TT:
+		return "TT"
+	case TDB:
+		return "TDB"
+	case GPS:
+		return "GPS"
+	case UT1:
+		return "UT1"
+	}
+	return fmt.Sprintf("Scale(%d)", int(s))
+}
+
+// Fixed offsets between the atomic time scales
+const (
+	ttMinusTAI  = 32184 * time.Millisecond
+	taiMinusGPS = 19 * time.Second
+)
+
+// seconds converts a number of seconds into a duration rounded to the nanosecond
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Round(s * 1e9))
+}
+
+// Convert returns the instant t, whose clock reads the time scale from, with its clock reading the time scale to.
+// dut1 is UT1-UTC in seconds, as published by the IERS, and is only used when converting to or from UT1.
+func Convert(t time.Time, from, to Scale, dut1 float64) time.Time {
+	if from == to {
+		return t.UTC()
+	}
+	return fromTAI(toTAI(t.UTC(), from, dut1), to, dut1)
+}
+
+// toTAI returns t, read in the time scale from, as TAI
+func toTAI(t time.Time, from Scale, dut1 float64) time.Time {
+	switch from {
+	case UT1:
+		t = t.Add(-seconds(dut1))
+		fallthrough
+	case UTC:
+		return t.Add(time.Duration(LeapSeconds(t)) * time.Second)
+	case TDB:
+		t = t.Add(-seconds(tdbMinusTT(t)))
+		fallthrough
+	case TT:
+		return t.Add(-ttMinusTAI)
+	case GPS:
+		return t.Add(taiMinusGPS)
+	}
+	return t
+}
+
+// fromTAI returns the TAI instant tai read in the time scale to
+func fromTAI(tai time.Time, to Scale, dut1 float64) time.Time {
+	switch to {
+	case UTC, UT1:
+		// the offset is that in effect at the UTC instant, which is found from a first guess made with the offset at
+		// the TAI instant
+		utc := tai.Add(-time.Duration(LeapSeconds(tai)) * time.Second)
+		utc = tai.Add(-time.Duration(LeapSeconds(utc)) * time.Second)
+		if to == UT1 {
+			return utc.Add(seconds(dut1))
+		}
+		return utc
+	case TT, TDB:
+		tt := tai.Add(ttMinusTAI)
+		if to == TDB {
+			return tt.Add(seconds(tdbMinusTT(tt)))
+		}
+		return tt
+	case GPS:
+		return tai.Add(-taiMinusGPS)
+	}
+	return tai
+}
+
+// tdbMinusTT returns TDB-TT in seconds at the instant tt, which may equally be given in TDB, from the leading periodic
+// terms of the difference. It is good to some tens of microseconds.
+func tdbMinusTT(tt time.Time) float64 {
+	days := float64(tt.Sub(j2000)) / float64(24*time.Hour)
+	g := (357.53 + 0.98560028*days) * math.Pi / 180.0
+	return 0.001657*math.Sin(g) + 0.00001385*math.Sin(2.0*g)
+}
+
+// j2000 is the J2000 epoch, 2000 January 1 12:00 TT
+var j2000 = time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// UTCToTT converts the UTC instant utc into Terrestrial Time
+func UTCToTT(utc time.Time) time.Time {
+	return Convert(utc, UTC, TT, 0)
+}
+
+// TTToUTC converts the Terrestrial Time instant tt into UTC
+func TTToUTC(tt time.Time) time.Time {
+	return Convert(tt, TT, UTC, 0)
+}
+
+// UTCToUT1 converts the UTC instant utc into UT1, given UT1-UTC in seconds
+func UTCToUT1(utc time.Time, dut1 float64) time.Time {
+	return utc.UTC().Add(seconds(dut1))
+}
+
+// UT1ToUTC converts the UT1 instant ut1 into UTC, given UT1-UTC in seconds
+func UT1ToUTC(ut1 time.Time, dut1 float64) time.Time {
+	return ut1.UTC().Add(-seconds(dut1))
+}
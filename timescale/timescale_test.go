@@ -0,0 +1,110 @@
+package timescale
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimescale(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "timescale Suite")
+}
+
+var _ = Describe("timescale", func() {
+	// Vallado, Fundamentals of Astrodynamics and Applications, example 3-15
+	utc := time.Date(2004, 4, 6, 7, 51, 28, 386009000, time.UTC)
+	dut1 := -0.4399619
+
+	Describe("LeapSeconds", func() {
+		It("should follow the built-in table", func() {
+			Expect(LeapSeconds(time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC))).To(Equal(10))
+			Expect(LeapSeconds(time.Date(1972, 6, 30, 23, 59, 59, 0, time.UTC))).To(Equal(10))
+			Expect(LeapSeconds(time.Date(1972, 7, 1, 0, 0, 0, 0, time.UTC))).To(Equal(11))
+			Expect(LeapSeconds(utc)).To(Equal(32))
+			Expect(LeapSeconds(time.Date(2005, 12, 31, 23, 59, 59, 999999999, time.UTC))).To(Equal(32))
+			Expect(LeapSeconds(time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC))).To(Equal(33))
+			Expect(LeapSeconds(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))).To(Equal(37))
+		})
+
+		It("should load the IERS Leap_Second.dat format", func() {
+			defer ResetLeapSeconds()
+			file := "#  File expires on 28 December 2099\n" +
+				"#\n" +
+				"#    MJD        Date        TAI-UTC (s)\n" +
+				"#           day month year\n" +
+				"#    ---    --------------   ------\n" +
+				"#\n" +
+				"    41317.0    1  1 1972       10\n" +
+				"    57754.0    1  1 2017       37\n" +
+				"    73780.0    1  1 2061       38\n"
+			Expect(LoadLeapSeconds(strings.NewReader(file))).To(Succeed())
+			Expect(LeapSeconds(time.Date(2060, 12, 31, 0, 0, 0, 0, time.UTC))).To(Equal(37))
+			Expect(LeapSeconds(time.Date(2061, 1, 1, 0, 0, 0, 0, time.UTC))).To(Equal(38))
+
+			ResetLeapSeconds()
+			Expect(LeapSeconds(time.Date(2061, 1, 1, 0, 0, 0, 0, time.UTC))).To(Equal(37))
+		})
+
+		It("should reject bad files and keep the table", func() {
+			defer ResetLeapSeconds()
+			Expect(LoadLeapSeconds(strings.NewReader("# nothing\n"))).To(Equal(ErrLeapSecondsEmpty))
+			Expect(LoadLeapSeconds(strings.NewReader("41317.0 1 1 1972 ten\n"))).ToNot(Succeed())
+			err := LoadLeapSeconds(strings.NewReader("57754.0 1 1 2017 37\n41317.0 1 1 1972 10\n"))
+			Expect(errors.Is(err, ErrLeapSecondsOrder)).To(BeTrue())
+			Expect(LeapSeconds(utc)).To(Equal(32))
+		})
+	})
+
+	Describe("Convert", func() {
+		It("should match Vallado's example", func() {
+			Expect(Convert(utc, UTC, TAI, dut1)).To(Equal(utc.Add(32 * time.Second)))
+			Expect(UTCToTT(utc)).To(Equal(time.Date(2004, 4, 6, 7, 52, 32, 570009000, time.UTC)))
+			Expect(Convert(utc, UTC, GPS, dut1)).To(Equal(utc.Add(13 * time.Second)))
+			Expect(Convert(utc, UTC, UT1, dut1)).To(Equal(time.Date(2004, 4, 6, 7, 51, 27, 946047100, time.UTC)))
+			Expect(UTCToUT1(utc, dut1)).To(Equal(Convert(utc, UTC, UT1, dut1)))
+
+			// the book gives TDB to a tenth of a millisecond
+			tdb := Convert(utc, UTC, TDB, dut1)
+			Expect(tdb.Sub(time.Date(2004, 4, 6, 7, 52, 32, 571600000, time.UTC)).Seconds()).To(BeNumerically("~", 0, 1e-4))
+		})
+
+		It("should round trip between every pair of scales", func() {
+			scales := []Scale{UTC, TAI, TT, TDB, GPS, UT1}
+			for _, t := range []time.Time{
+				utc,
+				time.Date(2005, 12, 31, 23, 59, 58, 0, time.UTC),
+				time.Date(2006, 1, 1, 0, 0, 1, 0, time.UTC),
+			} {
+				for _, from := range scales {
+					for _, to := range scales {
+						back := Convert(Convert(t, from, to, dut1), to, from, dut1)
+						Expect(back.Sub(t)).To(BeNumerically("~", 0, time.Microsecond), from.String()+" to "+to.String())
+					}
+				}
+			}
+		})
+
+		It("should step UTC across a leap second", func() {
+			before := Convert(time.Date(2005, 12, 31, 23, 59, 59, 0, time.UTC), UTC, TAI, 0)
+			after := Convert(time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC), UTC, TAI, 0)
+			Expect(after.Sub(before)).To(Equal(2 * time.Second))
+			Expect(TTToUTC(UTCToTT(time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC)))).To(Equal(time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC)))
+		})
+
+		It("should return UTC times", func() {
+			local := utc.In(time.FixedZone("UTC+2", 2*3600))
+			Expect(Convert(local, UTC, UTC, 0).Location()).To(Equal(time.UTC))
+			Expect(UT1ToUTC(UTCToUT1(local, dut1), dut1)).To(Equal(utc))
+		})
+	})
+
+	It("should name its scales", func() {
+		Expect(TDB.String()).To(Equal("TDB"))
+		Expect(Scale(42).String()).To(Equal("Scale(42)"))
+	})
+})
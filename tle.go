@@ -0,0 +1,364 @@
+package satellite
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Length of both lines of a two line element set. Older sets may lack the checksum in the last column.
+const (
+	tleLineLength           = 69
+	tleLineLengthNoChecksum = 68
+)
+
+// MaxCatalogNumber is the largest catalog number a TLE can hold, Z9999 in the Alpha-5 scheme
+const MaxCatalogNumber = 339999
+
+// Letters standing for 10 through 33 in the first column of an Alpha-5 catalog number. I and O are left out so they
+// can't be mistaken for 1 and 0.
+const alpha5Letters = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// ErrAlpha5 is returned for catalog numbers that can't be read or written in the Alpha-5 scheme
+var ErrAlpha5 = errors.New("invalid Alpha-5 catalog number")
+
+// ParseAlpha5 decodes a five column catalog number. Numbers from 100000 up are written in the Alpha-5 scheme, where a
+// leading letter stands for the two leading digits: A0001 is 100001 and Z9999 is 339999. Plain numbers up to 99999
+// are accepted with or without leading zeros or blanks.
+func ParseAlpha5(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || len(s) > 5 {
+		return 0, fmt.Errorf("%w: %q", ErrAlpha5, s)
+	}
+
+	var lead int64
+	digits := s
+	if c := s[0]; c >= 'A' && c <= 'Z' {
+		i := strings.IndexByte(alpha5Letters, c)
+		if i < 0 || len(s) != 5 {
+			return 0, fmt.Errorf("%w: %q", ErrAlpha5, s)
+		}
+		lead = int64(i + 10)
+		digits = s[1:]
+	}
+
+	n, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrAlpha5, s)
+	}
+	return lead*10000 + int64(n), nil
+}
+
+// FormatAlpha5 encodes a catalog number into the five columns of a TLE, zero padded up to 99999 and in the Alpha-5
+// scheme above that
+func FormatAlpha5(n int64) (string, error) {
+	if n < 0 || n > MaxCatalogNumber {
+		return "", fmt.Errorf("%w: %d is out of range", ErrAlpha5, n)
+	}
+	if n < 100000 {
+		return fmt.Sprintf("%05d", n), nil
+	}
+	return fmt.Sprintf("%c%04d", alpha5Letters[n/10000-10], n%10000), nil
+}
+
+// Errors describing the structure of a two line element set. They are wrapped in a *TLEError naming the line.
+var (
+	ErrTLELineLength      = errors.New("line is not 69 characters long, or 68 without a checksum")
+	ErrTLELineNumber      = errors.New("line does not start with its line number")
+	ErrTLECatalogMismatch = errors.New("catalog number does not match line 1")
+	ErrTLEChecksum        = errors.New("checksum mismatch")
+	ErrTLEClassification  = errors.New("classification is not U, C or S")
+	ErrTLEFieldRange      = errors.New("value does not fit in the field")
+)
+
+// ChecksumError reports a TLE line whose checksum doesn't match its contents. It is wrapped in a *TLEError naming the
+// line and matches ErrTLEChecksum.
+type ChecksumError struct {
+	// Expected is the checksum calculated from the line
+	Expected int
+	// Actual is the checksum found in column 69, or -1 if the line has no checksum
+	Actual int
+}
+
+func (e *ChecksumError) Error() string {
+	if e.Actual < 0 {
+		return fmt.Sprintf("%v: line has no checksum, expected %d", ErrTLEChecksum, e.Expected)
+	}
+	return fmt.Sprintf("%v: found %d, expected %d", ErrTLEChecksum, e.Actual, e.Expected)
+}
+
+func (e *ChecksumError) Is(target error) bool {
+	return target == ErrTLEChecksum
+}
+
+// tleChecksum calculates the modulo 10 checksum of the first 68 columns of a TLE line: the sum of all digits, with
+// each minus sign counting as 1
+func tleChecksum(line string) int {
+	sum := 0
+	for i := 0; i < len(line) && i < tleLineLengthNoChecksum; i++ {
+		switch c := line[i]; {
+		case c >= '0' && c <= '9':
+			sum += int(c - '0')
+		case c == '-':
+			sum++
+		}
+	}
+	return sum % 10
+}
+
+// TLEError describes a field of a two line element set that couldn't be parsed
+type TLEError struct {
+	// Line is the line of the element set the error was found in, 1 or 2
+	Line int
+	// Start and End are the first and last column of the field, counted from 1 as in the TLE format documentation
+	Start, End int
+	// Field is the name of the field
+	Field string
+	// Text is the content of the field, cut short if the line was too short to hold all of it
+	Text string
+	// Err is the underlying error, such as a *strconv.NumError
+	Err error
+}
+
+func (e *TLEError) Error() string {
+	return fmt.Sprintf("tle line %d columns %d-%d (%s) %q: %v", e.Line, e.Start, e.End, e.Field, e.Text, e.Err)
+}
+
+func (e *TLEError) Unwrap() error {
+	return e.Err
+}
+
+// tleParser reads fields out of the lines of a two line element set. Once a field fails to parse every later call is
+// a no-op, so a whole element set can be read before checking err.
+type tleParser struct {
+	lines [2]string
+	err   error
+}
+
+// text returns the field in columns start through end of line, counted from 1. The field is cut short if the line
+// is shorter than end.
+func (p *tleParser) text(line, start, end int) string {
+	l := p.lines[line-1]
+	if start > len(l) {
+		return ""
+	}
+	if end > len(l) {
+		end = len(l)
+	}
+	return l[start-1 : end]
+}
+
+// fail records the first error found
+func (p *tleParser) fail(line, start, end int, field string, err error) {
+	if p.err == nil {
+		p.err = &TLEError{Line: line, Start: start, End: end, Field: field, Text: p.text(line, start, end), Err: err}
+	}
+}
+
+// checkLine verifies the length, line number and, unless skipChecksum is set, the checksum of line
+func (p *tleParser) checkLine(line int, skipChecksum bool) {
+	l := p.lines[line-1]
+	if len(l) != tleLineLength && len(l) != tleLineLengthNoChecksum {
+		p.fail(line, 1, tleLineLength, "line", ErrTLELineLength)
+		return
+	}
+	if p.text(line, 1, 1) != strconv.Itoa(line) {
+		p.fail(line, 1, 1, "line number", ErrTLELineNumber)
+		return
+	}
+	if skipChecksum {
+		return
+	}
+
+	expected := tleChecksum(l)
+	actual := -1
+	if len(l) == tleLineLength {
+		if c := l[tleLineLength-1]; c >= '0' && c <= '9' {
+			actual = int(c - '0')
+		}
+	}
+	if actual != expected {
+		p.fail(line, tleLineLength, tleLineLength, "checksum", &ChecksumError{Expected: expected, Actual: actual})
+	}
+}
+
+// parseFloat parses a field after removing its blanks. prepare, if not nil, rewrites the field into a form
+// strconv.ParseFloat accepts.
+func (p *tleParser) parseFloat(line, start, end int, field string, prepare func(string) string) float64 {
+	if p.err != nil {
+		return 0
+	}
+	s := p.text(line, start, end)
+	if prepare != nil {
+		s = prepare(s)
+	}
+	ret, err := strconv.ParseFloat(strings.Replace(s, " ", "", -1), 64)
+	if err != nil {
+		p.fail(line, start, end, field, err)
+	}
+	return ret
+}
+
+// parseInt parses a field holding a decimal integer, ignoring leading and trailing blanks
+func (p *tleParser) parseInt(line, start, end int, field string) int64 {
+	if p.err != nil {
+		return 0
+	}
+	ret, err := strconv.ParseInt(strings.TrimSpace(p.text(line, start, end)), 10, 64)
+	if err != nil {
+		p.fail(line, start, end, field, err)
+	}
+	return ret
+}
+
+// parseOptionalInt is parseInt for fields that may be left blank, which reads as 0
+func (p *tleParser) parseOptionalInt(line, start, end int, field string) int64 {
+	if p.err != nil || strings.TrimSpace(p.text(line, start, end)) == "" {
+		return 0
+	}
+	return p.parseInt(line, start, end, field)
+}
+
+// parseClassification parses the classification in column 8 of line 1. A blank is read as U.
+func (p *tleParser) parseClassification() byte {
+	if p.err != nil {
+		return 0
+	}
+	switch c := p.text(1, 8, 8)[0]; c {
+	case 'U', 'C', 'S':
+		return c
+	case ' ':
+		return 'U'
+	}
+	p.fail(1, 8, 8, "classification", ErrTLEClassification)
+	return 0
+}
+
+// parseCatalogNumber parses the catalog number in columns 3 through 7 of line
+func (p *tleParser) parseCatalogNumber(line int) int64 {
+	if p.err != nil {
+		return 0
+	}
+	ret, err := ParseAlpha5(p.text(line, 3, 7))
+	if err != nil {
+		p.fail(line, 3, 7, "catalog number", err)
+	}
+	return ret
+}
+
+// Rewrites a field in the TLE's implied decimal point notation, such as " 12345-4", into "+.12345e-4"
+func impliedDecimal(s string) string {
+	return s[:1] + "." + s[1:6] + "e" + s[6:]
+}
+
+// Rewrites the eccentricity field, which has an implied leading decimal point
+func leadingDecimal(s string) string {
+	return "." + s
+}
+
+// FormatTLE writes an element set as the two lines of a TLE, checksums included. A *TLEError wrapping
+// ErrTLEFieldRange names the first field whose value doesn't fit in its columns.
+func FormatTLE(e ElementSet) (line1, line2 string, err error) {
+	w := tleWriter{}
+	w.lines[0] = []byte(strings.Repeat(" ", tleLineLengthNoChecksum))
+	w.lines[1] = []byte(strings.Repeat(" ", tleLineLengthNoChecksum))
+
+	catalogNumber, err := FormatAlpha5(e.CatalogNumber)
+	if err != nil {
+		return "", "", &TLEError{Line: 1, Start: 3, End: 7, Field: "catalog number", Err: err}
+	}
+	classification := e.Classification
+	if classification == 0 {
+		classification = 'U'
+	}
+	if classification != 'U' && classification != 'C' && classification != 'S' {
+		return "", "", &TLEError{Line: 1, Start: 8, End: 8, Field: "classification", Text: string(classification), Err: ErrTLEClassification}
+	}
+
+	// LINE 1 BEGIN
+	w.put(1, 1, 1, "line number", "1")
+	w.put(1, 3, 7, "catalog number", catalogNumber)
+	w.put(1, 8, 8, "classification", string(classification))
+	w.put(1, 10, 17, "international designator", fmt.Sprintf("%-8s", e.IntlDesignator))
+	w.put(1, 19, 20, "epoch year", fmt.Sprintf("%02d", e.EpochYear))
+	w.put(1, 21, 32, "epoch day", fmt.Sprintf("%012.8f", e.EpochDay))
+	w.put(1, 34, 43, "first derivative of mean motion", formatLeadingDecimal(e.MeanMotionDot, 8))
+	w.put(1, 45, 52, "second derivative of mean motion", formatImpliedDecimal(e.MeanMotionDDot))
+	w.put(1, 54, 61, "bstar drag term", formatImpliedDecimal(e.BStar))
+	w.put(1, 63, 63, "ephemeris type", strconv.FormatInt(e.EphemerisType, 10))
+	w.put(1, 65, 68, "element set number", fmt.Sprintf("%4d", e.ElementSetNumber))
+	// LINE 1 END
+
+	// LINE 2 BEGIN
+	w.put(2, 1, 1, "line number", "2")
+	w.put(2, 3, 7, "catalog number", catalogNumber)
+	w.put(2, 9, 16, "inclination", fmt.Sprintf("%8.4f", e.Inclination))
+	w.put(2, 18, 25, "right ascension of ascending node", fmt.Sprintf("%8.4f", e.RightAscension))
+	w.put(2, 27, 33, "eccentricity", fmt.Sprintf("%07.0f", e.Eccentricity*1e7))
+	w.put(2, 35, 42, "argument of perigee", fmt.Sprintf("%8.4f", e.ArgPerigee))
+	w.put(2, 44, 51, "mean anomaly", fmt.Sprintf("%8.4f", e.MeanAnomaly))
+	w.put(2, 53, 63, "mean motion", fmt.Sprintf("%11.8f", e.MeanMotion))
+	w.put(2, 64, 68, "revolution number at epoch", fmt.Sprintf("%5d", e.RevNumber))
+	// LINE 2 END
+
+	if w.err != nil {
+		return "", "", w.err
+	}
+	line1 = string(w.lines[0])
+	line2 = string(w.lines[1])
+	return line1 + strconv.Itoa(tleChecksum(line1)), line2 + strconv.Itoa(tleChecksum(line2)), nil
+}
+
+// tleWriter fills in the columns of a two line element set, remembering the first field that didn't fit
+type tleWriter struct {
+	lines [2][]byte
+	err   error
+}
+
+// put writes text into columns start through end of line, counted from 1
+func (w *tleWriter) put(line, start, end int, field, text string) {
+	if w.err != nil {
+		return
+	}
+	if len(text) != end-start+1 {
+		w.err = &TLEError{Line: line, Start: start, End: end, Field: field, Text: text, Err: ErrTLEFieldRange}
+		return
+	}
+	copy(w.lines[line-1][start-1:end], text)
+}
+
+// formatSign returns the sign column of a signed field, which is blank for positive values
+func formatSign(v float64) string {
+	if math.Signbit(v) {
+		return "-"
+	}
+	return " "
+}
+
+// formatLeadingDecimal writes v with the given number of decimals and without the zero before the decimal point,
+// such as " .00002182" or "-.00002182"
+func formatLeadingDecimal(v float64, decimals int) string {
+	return formatSign(v) + strings.TrimPrefix(strconv.FormatFloat(math.Abs(v), 'f', decimals, 64), "0")
+}
+
+// formatImpliedDecimal writes v in the TLE's implied decimal point notation, such as "-11606-4" for -0.11606e-4
+func formatImpliedDecimal(v float64) string {
+	if v == 0 {
+		return formatSign(v) + "00000-0"
+	}
+	// d.dddde±xx holds the same digits as 0.ddddde±(xx+1)
+	s := strconv.FormatFloat(math.Abs(v), 'e', 4, 64)
+	exp, err := strconv.Atoi(s[7:])
+	if err != nil {
+		return ""
+	}
+	exp++
+	expSign := "+"
+	if exp < 0 {
+		expSign = "-"
+		exp = -exp
+	}
+	return formatSign(v) + s[:1] + s[2:6] + expSign + strconv.Itoa(exp)
+}
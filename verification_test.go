@@ -0,0 +1,259 @@
+package satellite
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"bufio"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// verificationTLE is an element set of SGP4-VER.TLE with the start, stop and step of its run in minutes from epoch
+type verificationTLE struct {
+	line1, line2      string
+	start, stop, step float64
+}
+
+// verificationRecord is one line of tcppver.out: the state at tsince or the error code propagation stopped with
+type verificationRecord struct {
+	tsince    float64
+	pos, vel  Vector3
+	errorCode int
+}
+
+// Tolerances for agreement with tcppver.out, which prints positions to 1e-8 km and velocities to 1e-9 km/s
+const (
+	verificationPosTolerance = 1e-6 // km
+	verificationVelTolerance = 1e-6 // km/s
+)
+
+var _ = Describe("Vallado verification suite", func() {
+	tles, err := readVerificationTLEs("testdata/SGP4-VER.TLE")
+	if err != nil {
+		panic(err)
+	}
+	reference, err := readVerificationOutput("testdata/tcppver.out")
+	if err != nil {
+		panic(err)
+	}
+
+	It("should have reference output for every element set", func() {
+		Expect(tles).ToNot(BeEmpty())
+		for satnum := range tles {
+			Expect(reference).To(HaveKey(satnum))
+		}
+	})
+
+	It("should include the error code cases", func() {
+		var errorRecords int
+		for _, records := range reference {
+			for _, record := range records {
+				if record.errorCode != 0 {
+					errorRecords++
+				}
+			}
+		}
+		if errorRecords == 0 {
+			Skip("testdata holds a subset of the verification cases without error records; vendor the complete " +
+				"SGP4-VER.TLE and tcppver.out to check them")
+		}
+	})
+
+	satnums := make([]string, 0, len(tles))
+	for satnum := range tles {
+		satnums = append(satnums, satnum)
+	}
+	sort.Strings(satnums)
+
+	for _, satnum := range satnums {
+		tle := tles[satnum]
+		records := reference[satnum]
+		// some of the verification element sets were edited by hand without fixing their checksums
+		sat, err := TLEToSat(tle.line1, tle.line2, GravityWGS72, SkipChecksum())
+
+		Context("Satnum "+satnum, func() {
+			It("should initialize", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+			if err != nil {
+				return
+			}
+
+			It("should have reference output for every time of the run", func() {
+				times := verificationTimes(tle)
+				Expect(len(records)).To(BeNumerically("<=", len(times)))
+				for i, record := range records {
+					Expect(record.tsince).To(BeNumerically("~", times[i], 1e-6))
+					// the reference run stops at its first error
+					if record.errorCode != 0 {
+						Expect(i).To(Equal(len(records) - 1))
+						return
+					}
+				}
+				Expect(records).To(HaveLen(len(times)))
+			})
+
+			for _, record := range records {
+				record := record
+				It("should match the reference at "+strconv.FormatFloat(record.tsince, 'f', 8, 64), func() {
+					expectVerificationRecord(sat, record)
+				})
+			}
+		})
+	}
+
+	It("should check error records against the propagation error", func() {
+		sat, err := TLEToSat(decayingISSLine1, issLine2, GravityWGS72)
+		Expect(err).ToNot(HaveOccurred())
+		records, err := parseVerificationOutput(strings.NewReader("25544 xx\n" +
+			"# *** error: t:=   420.000000 *** code =   6\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(records["25544"]).To(Equal([]verificationRecord{{tsince: 420, errorCode: 6}}))
+		expectVerificationRecord(sat, records["25544"][0])
+	})
+})
+
+// expectVerificationRecord propagates sat to the time of record and expects the state or the error code it holds.
+// The reference code reports errors at the time of the propagation that failed, as does PropagationError.
+func expectVerificationRecord(sat *Satellite, record verificationRecord) {
+	pos, vel, err := PropagateMinutes(sat, record.tsince)
+	if record.errorCode != 0 {
+		var propErr *PropagationError
+		Expect(errors.As(err, &propErr)).To(BeTrue())
+		Expect(propErr.Code).To(Equal(record.errorCode))
+		Expect(propErr.Tsince).To(Equal(record.tsince))
+		return
+	}
+	Expect(err).ToNot(HaveOccurred())
+
+	Expect(pos.X).To(BeNumerically("~", record.pos.X, verificationPosTolerance))
+	Expect(pos.Y).To(BeNumerically("~", record.pos.Y, verificationPosTolerance))
+	Expect(pos.Z).To(BeNumerically("~", record.pos.Z, verificationPosTolerance))
+	Expect(vel.X).To(BeNumerically("~", record.vel.X, verificationVelTolerance))
+	Expect(vel.Y).To(BeNumerically("~", record.vel.Y, verificationVelTolerance))
+	Expect(vel.Z).To(BeNumerically("~", record.vel.Z, verificationVelTolerance))
+}
+
+// verificationTimes returns the times the reference driver propagates tle to: its epoch, then from start to stop by
+// step, ending on stop
+func verificationTimes(tle verificationTLE) []float64 {
+	times := []float64{0}
+	tsince := tle.start
+	if math.Abs(tsince) > 1e-8 {
+		tsince -= tle.step
+	}
+	for tsince < tle.stop {
+		tsince += tle.step
+		if tsince > tle.stop {
+			tsince = tle.stop
+		}
+		times = append(times, tsince)
+	}
+	return times
+}
+
+// readVerificationTLEs reads SGP4-VER.TLE into its element sets keyed by catalog number
+func readVerificationTLEs(name string) (map[string]verificationTLE, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	tles := map[string]verificationTLE{}
+	var line1 string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		switch {
+		case strings.HasPrefix(line, "1 "):
+			line1 = line
+		case strings.HasPrefix(line, "2 ") && len(line) >= tleLineLength:
+			satnum, err := ParseAlpha5(line[2:7])
+			if err != nil {
+				return nil, err
+			}
+			run := strings.Fields(line[tleLineLength:])
+			if len(run) != 3 {
+				return nil, errors.New("no start, stop and step in " + name + ": " + line)
+			}
+			tle := verificationTLE{line1: line1, line2: line[:tleLineLength]}
+			for i, v := range []*float64{&tle.start, &tle.stop, &tle.step} {
+				if *v, err = strconv.ParseFloat(run[i], 64); err != nil {
+					return nil, err
+				}
+			}
+			tles[strconv.FormatInt(satnum, 10)] = tle
+		}
+	}
+	return tles, scanner.Err()
+}
+
+// readVerificationOutput reads tcppver.out into the records of each element set keyed by catalog number. Each set
+// starts with a line holding its catalog number and "xx"; errors are reported on lines like
+// "# *** error: t:= 1860.000000 *** code =   6".
+func readVerificationOutput(name string) (map[string][]verificationRecord, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseVerificationOutput(f)
+}
+
+// parseVerificationOutput reads output in the format of tcppver.out from r
+func parseVerificationOutput(r io.Reader) (map[string][]verificationRecord, error) {
+	reference := map[string][]verificationRecord{}
+	var satnum string
+	var err error
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 0:
+		case len(fields) == 2 && fields[1] == "xx":
+			n, err := ParseAlpha5(fields[0])
+			if err != nil {
+				return nil, err
+			}
+			satnum = strconv.FormatInt(n, 10)
+		case fields[0] == "#":
+			line := strings.Join(fields, " ")
+			t := strings.Index(line, "t:=")
+			code := strings.Index(line, "code =")
+			if t < 0 || code < 0 {
+				continue
+			}
+			var record verificationRecord
+			if record.tsince, err = strconv.ParseFloat(strings.Fields(line[t+3:])[0], 64); err != nil {
+				return nil, err
+			}
+			if record.errorCode, err = strconv.Atoi(strings.Fields(line[code+6:])[0]); err != nil {
+				return nil, err
+			}
+			reference[satnum] = append(reference[satnum], record)
+		default:
+			if len(fields) < 7 {
+				return nil, errors.New("short record: " + scanner.Text())
+			}
+			var v [7]float64
+			for i := range v {
+				if v[i], err = strconv.ParseFloat(fields[i], 64); err != nil {
+					return nil, err
+				}
+			}
+			reference[satnum] = append(reference[satnum], verificationRecord{
+				tsince: v[0],
+				pos:    Vector3{X: v[1], Y: v[2], Z: v[3]},
+				vel:    Vector3{X: v[4], Y: v[5], Z: v[6]},
+			})
+		}
+	}
+	return reference, scanner.Err()
+}